@@ -1,5 +1,7 @@
 package pasdk
 
+import "context"
+
 // AccountRequest returns information about an account and its available plan types.
 type AccountRequest struct{}
 
@@ -10,25 +12,49 @@ type AccountResponse struct {
 	Plans       []Plan `json:"plans"`        // A list of available plan types for this merchant.
 }
 
-// Fetch executes the request.
-func (request AccountRequest) Fetch() (response *AccountResponse, err *PASDKError) {
+// Fetch executes the request using the credentials passed to Initialise.
+func (request AccountRequest) Fetch() (*AccountResponse, *PASDKError) {
+	return defaultClient.AccountContext(context.Background(), request)
+}
+
+// FetchContext executes the request using the credentials passed to Initialise. The
+// request is abandoned as soon as ctx is cancelled or its deadline passes.
+func (request AccountRequest) FetchContext(ctx context.Context) (*AccountResponse, *PASDKError) {
+	return defaultClient.AccountContext(ctx, request)
+}
+
+// Validate checks the request without sending it. An AccountRequest has no fields, so it
+// is always valid and Validate always returns nil.
+func (request AccountRequest) Validate() *PASDKError {
+	return nil
+}
+
+// Account executes the given request using this client's credentials.
+func (client *Client) Account(request AccountRequest) (*AccountResponse, *PASDKError) {
+	return client.AccountContext(context.Background(), request)
+}
+
+// AccountContext is like Account, but the request is abandoned as soon as ctx is
+// cancelled or its deadline passes.
+func (client *Client) AccountContext(ctx context.Context, request AccountRequest) (response *AccountResponse, err *PASDKError) {
+	defer client.logFailure(ctx, "account", &err)
 	defer catchGenericPanic(&response, &err)
 
-	signature := generateSignature([]string{}, userCredentials.APISecret)
+	signature := generateSignature([]requestParam{}, client.credentials.APISecret)
 
 	// Alphabetically sorted.
-	requestParams := []string{
-		"api_key=" + userCredentials.APIKey,
-		"signature=" + signature,
+	requestParams := []requestParam{
+		{"api_key", client.credentials.APIKey},
+		{"signature", signature},
 	}
 
-	requestURL, err := getRequestURL()
+	requestURL, err := client.getRequestURL()
 
 	if err != nil {
 		return nil, err.Wrap("failed determining request URL: ")
 	}
 
-	response, err = makeAPIGETRequest[AccountResponse](requestParams, requestURL+"account")
+	response, err = makeAPIGETRequest[AccountResponse](ctx, client, requestParams, requestURL+"account")
 
 	if err != nil {
 		return nil, err.Wrap("API request failed: ")
@@ -0,0 +1,269 @@
+package pasdk
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AccountCacheOptions controls how long an AccountCache keeps the account it fetched.
+type AccountCacheOptions struct {
+	// TTL is how long a fetched account is used before it is fetched again. Defaults to
+	// 5 minutes.
+	TTL time.Duration
+
+	// StaleWhileRevalidate is how long after TTL has passed the old account is still
+	// returned straight away while a fresh one is fetched in the background. After that,
+	// callers wait for the account to be fetched again. Zero means callers always wait
+	// once TTL has passed.
+	StaleWhileRevalidate time.Duration
+
+	// OnChange, if set, is called when a fetched account's plans differ from those
+	// fetched previously, including changes to their commission terms. It isn't called
+	// for the first fetch. It is called from the goroutine that fetched the account, but
+	// never by more than one goroutine at a time, and never for an older change after a
+	// newer one.
+	OnChange func(previous AccountResponse, current AccountResponse)
+}
+
+// AccountCache keeps a copy of the account and its plans, so that they don't have to be
+// fetched from the API every time they are needed. It is safe for concurrent use, and
+// callers that need the account at the same time share a single request for it. Call
+// Close once the cache is no longer needed.
+type AccountCache struct {
+	client  *Client
+	options AccountCacheOptions
+	now     func() time.Time
+	ctx     context.Context // Used for every fetch, and cancelled by Close.
+	cancel  context.CancelFunc
+
+	mutex       sync.Mutex
+	account     *AccountResponse // The cached account, or nil if there isn't one.
+	fetchedAt   time.Time        // When account was fetched.
+	lastFetched *AccountResponse // The account from the last successful fetch, for spotting changes.
+	lastStarted int              // When the last successful fetch was started, as a count of the fetches before it.
+	fetches     int              // The number of fetches started so far.
+	fetch       *accountFetch    // The fetch in progress, if any.
+	generation  int              // Incremented by Invalidate, so fetches started before it are discarded.
+	changes     int              // The number of changes found so far.
+	closed      bool
+
+	changeMutex sync.Mutex // Held while calling OnChange.
+	notified    int        // The number of the last change passed to OnChange. Guarded by changeMutex.
+}
+
+// The longest a single fetch of the account can take, including any retries.
+const accountFetchTimeout = time.Minute
+
+// A single request for the account, shared by everyone waiting for it.
+type accountFetch struct {
+	done    chan struct{} // Closed once the request has finished.
+	number  int           // How many fetches were started before this one.
+	account *AccountResponse
+	err     *PASDKError
+}
+
+// A change to the account's plans, found by a fetch.
+type accountChange struct {
+	number   int // Counts up with each change found, so that late notifications can be skipped.
+	previous AccountResponse
+	current  AccountResponse
+}
+
+// NewAccountCache creates an AccountCache that fetches the account using the credentials
+// passed to Initialise.
+func NewAccountCache(options AccountCacheOptions) *AccountCache {
+	return defaultClient.NewAccountCache(options)
+}
+
+// NewAccountCache creates an AccountCache that fetches the account using this client.
+// Nothing is fetched until Get is first called.
+func (client *Client) NewAccountCache(options AccountCacheOptions) *AccountCache {
+	if options.TTL <= 0 {
+		options.TTL = 5 * time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &AccountCache{client: client, options: options, now: time.Now, ctx: ctx, cancel: cancel}
+}
+
+// Get returns the account, fetching it if it isn't cached or has expired. If the cached
+// account expired less than StaleWhileRevalidate ago, it is returned straight away and
+// fetched again in the background. Errors aren't cached, so the next call tries again.
+// Once the cache has been closed, Get fails unless the cached account is still usable.
+//
+// The returned account is shared with other callers and must not be modified.
+func (cache *AccountCache) Get(ctx context.Context) (*AccountResponse, *PASDKError) {
+	cache.mutex.Lock()
+
+	if cache.account != nil {
+		age := cache.now().Sub(cache.fetchedAt)
+
+		if age < cache.options.TTL {
+			defer cache.mutex.Unlock()
+			return cache.account, nil
+		}
+
+		if age < cache.options.TTL+cache.options.StaleWhileRevalidate {
+			defer cache.mutex.Unlock()
+			cache.startFetch()
+
+			return cache.account, nil
+		}
+	}
+
+	fetch := cache.startFetch()
+	cache.mutex.Unlock()
+
+	select {
+	case <-fetch.done:
+		return fetch.account, fetch.err
+	case <-ctx.Done():
+		return nil, buildCancelledError("waiting for the account was cancelled: " + ctx.Err().Error()).withCause(ctx.Err())
+	}
+}
+
+// Invalidate discards the cached account, so that the next call to Get fetches it again.
+// The result of any fetch already in progress isn't cached.
+func (cache *AccountCache) Invalidate() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.account = nil
+	cache.fetch = nil
+	cache.generation++
+}
+
+// Close stops any fetch in progress and prevents new ones from starting. Callers waiting
+// for a fetch that is stopped receive a cancelled error.
+func (cache *AccountCache) Close() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.closed = true
+	cache.cancel()
+}
+
+// Starts fetching the account in the background unless a fetch is already in progress,
+// and returns the fetch. The fetch isn't tied to any caller's context, so one caller
+// giving up doesn't affect the others, but it is stopped by Close or if it takes longer
+// than accountFetchTimeout. The mutex must be held.
+func (cache *AccountCache) startFetch() *accountFetch {
+	if cache.fetch != nil {
+		return cache.fetch
+	}
+
+	fetch := &accountFetch{done: make(chan struct{}), number: cache.fetches}
+
+	if cache.closed {
+		fetch.err = buildCancelledError("the account cache has been closed")
+		close(fetch.done)
+
+		return fetch
+	}
+
+	cache.fetch = fetch
+	cache.fetches++
+	generation := cache.generation
+
+	go func() {
+		ctx, cancel := context.WithTimeout(cache.ctx, accountFetchTimeout)
+		defer cancel()
+
+		fetch.account, fetch.err = cache.client.AccountContext(ctx, AccountRequest{})
+		change := cache.finishFetch(fetch, generation)
+		close(fetch.done)
+
+		if change != nil {
+			cache.notifyChange(*change)
+		}
+	}()
+
+	return fetch
+}
+
+// Caches the result of a finished fetch. If the plans have changed since the previous
+// fetch, the change is returned. A fetch that finishes after one started later, which can
+// happen after Invalidate, is ignored.
+func (cache *AccountCache) finishFetch(fetch *accountFetch, generation int) *accountChange {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.fetch == fetch {
+		cache.fetch = nil
+	}
+
+	if fetch.err != nil {
+		return nil
+	}
+
+	previous := cache.lastFetched
+
+	// A fetch started after this one has already finished, so this one is out of date.
+	if previous != nil && fetch.number < cache.lastStarted {
+		return nil
+	}
+
+	if generation == cache.generation {
+		cache.account = fetch.account
+		cache.fetchedAt = cache.now()
+	}
+
+	cache.lastFetched = fetch.account
+	cache.lastStarted = fetch.number
+
+	if previous == nil || plansEqual(previous.Plans, fetch.account.Plans) {
+		return nil
+	}
+
+	cache.changes++
+
+	return &accountChange{cache.changes, *previous, *fetch.account}
+}
+
+// Calls OnChange, if it is set, unless a later change has already been passed to it.
+func (cache *AccountCache) notifyChange(change accountChange) {
+	if cache.options.OnChange == nil {
+		return
+	}
+
+	cache.changeMutex.Lock()
+	defer cache.changeMutex.Unlock()
+
+	if change.number <= cache.notified {
+		return
+	}
+
+	cache.notified = change.number
+	cache.options.OnChange(change.previous, change.current)
+}
+
+// Returns true if the two lists contain the same plans with the same terms, in the same order.
+func plansEqual(first []Plan, second []Plan) bool {
+	if len(first) != len(second) {
+		return false
+	}
+
+	for i := range first {
+		a, b := first[i], second[i]
+
+		if a.ID != b.ID || a.Name != b.Name || a.Instalments != b.Instalments ||
+			a.DepositRequired != b.DepositRequired || a.APR != b.APR || a.Frequency != b.Frequency ||
+			a.CommissionRate != b.CommissionRate || !moneyPointersEqual(a.MinAmount, b.MinAmount) ||
+			!moneyPointersEqual(a.MaxAmount, b.MaxAmount) ||
+			!moneyPointersEqual(a.CommissionFixedFee, b.CommissionFixedFee) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func moneyPointersEqual(first *Money, second *Money) bool {
+	if first == nil || second == nil {
+		return first == second
+	}
+
+	return *first == *second
+}
@@ -0,0 +1,357 @@
+package pasdk
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A fake API for account cache tests. Requests wait until release is closed, if set.
+type accountCacheTestAPI struct {
+	requests int32
+	release  chan struct{}
+
+	mutex          sync.Mutex
+	commissionRate string
+	statusCode     int
+	now            time.Time
+}
+
+func newAccountCacheTestAPI() *accountCacheTestAPI {
+	return &accountCacheTestAPI{commissionRate: "8.50", statusCode: 200, now: time.Now()}
+}
+
+func (api *accountCacheTestAPI) newCache(options AccountCacheOptions) *AccountCache {
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithTransport(RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&api.requests, 1)
+
+			if api.release != nil {
+				select {
+				case <-api.release:
+				case <-request.Context().Done():
+					return nil, request.Context().Err()
+				}
+			}
+
+			api.mutex.Lock()
+			defer api.mutex.Unlock()
+
+			return newStaticTransport(api.statusCode, `{"status":"ok","msg":null,"data":{"plans":[`+
+				`{"plan_id":1,"name":"4-Payment","commission_rate":"`+api.commissionRate+`"}]}}`)(request)
+		})))
+
+	cache := client.NewAccountCache(options)
+	cache.now = api.clock
+
+	return cache
+}
+
+func (api *accountCacheTestAPI) clock() time.Time {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	return api.now
+}
+
+func (api *accountCacheTestAPI) advance(duration time.Duration) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	api.now = api.now.Add(duration)
+}
+
+func (api *accountCacheTestAPI) set(commissionRate string, statusCode int) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	api.commissionRate = commissionRate
+	api.statusCode = statusCode
+}
+
+func (api *accountCacheTestAPI) requestCount() int {
+	return int(atomic.LoadInt32(&api.requests))
+}
+
+// Waits until the API has received the given number of requests.
+func (api *accountCacheTestAPI) waitForRequests(t *testing.T, count int) {
+	for deadline := time.Now().Add(time.Second); api.requestCount() < count; {
+		if time.Now().After(deadline) {
+			t.Fatal("expected", count, "requests but got", api.requestCount())
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func Test_AccountCache_CachesUntilTTL(t *testing.T) {
+	api := newAccountCacheTestAPI()
+	cache := api.newCache(AccountCacheOptions{TTL: time.Minute})
+
+	first, err := cache.Get(context.Background())
+
+	if err != nil || first.Plans[0].CommissionRate != "8.50" {
+		t.Fatal(first, err)
+	}
+
+	api.set("9.00", 200)
+	api.advance(59 * time.Second)
+
+	if second, _ := cache.Get(context.Background()); second != first || api.requestCount() != 1 {
+		t.Error(api.requestCount())
+	}
+
+	api.advance(time.Second)
+
+	third, err := cache.Get(context.Background())
+
+	if err != nil || third.Plans[0].CommissionRate != "9.00" || api.requestCount() != 2 {
+		t.Error(third, err)
+	}
+}
+
+func Test_AccountCache_SharesInFlightFetch(t *testing.T) {
+	api := newAccountCacheTestAPI()
+	api.release = make(chan struct{})
+	cache := api.newCache(AccountCacheOptions{})
+
+	results := make(chan *AccountResponse, 10)
+
+	for i := 0; i < 10; i++ {
+		go func() {
+			account, _ := cache.Get(context.Background())
+			results <- account
+		}()
+	}
+
+	api.waitForRequests(t, 1)
+	time.Sleep(10 * time.Millisecond)
+	close(api.release)
+
+	first := <-results
+
+	for i := 1; i < 10; i++ {
+		if account := <-results; account == nil || account != first {
+			t.Error(account)
+		}
+	}
+
+	if api.requestCount() != 1 {
+		t.Error(api.requestCount())
+	}
+}
+
+func Test_AccountCache_ServesStaleWhileRevalidating(t *testing.T) {
+	api := newAccountCacheTestAPI()
+	cache := api.newCache(AccountCacheOptions{TTL: time.Minute, StaleWhileRevalidate: time.Minute})
+
+	first, _ := cache.Get(context.Background())
+
+	api.set("9.00", 200)
+	api.advance(90 * time.Second)
+
+	if stale, _ := cache.Get(context.Background()); stale != first {
+		t.Error("expected the stale account")
+	}
+
+	api.waitForRequests(t, 2)
+
+	for deadline := time.Now().Add(time.Second); ; {
+		fresh, _ := cache.Get(context.Background())
+
+		if fresh.Plans[0].CommissionRate == "9.00" {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("the account wasn't refreshed")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if api.requestCount() != 2 {
+		t.Error(api.requestCount())
+	}
+
+	// Once the stale period has passed too, callers wait for a fresh account.
+	api.set("9.50", 200)
+	api.advance(2 * time.Minute)
+
+	if account, _ := cache.Get(context.Background()); account.Plans[0].CommissionRate != "9.50" {
+		t.Error(account)
+	}
+}
+
+func Test_AccountCache_Invalidate(t *testing.T) {
+	api := newAccountCacheTestAPI()
+	cache := api.newCache(AccountCacheOptions{})
+
+	cache.Get(context.Background())
+	cache.Invalidate()
+	cache.Get(context.Background())
+
+	if api.requestCount() != 2 {
+		t.Error(api.requestCount())
+	}
+}
+
+func Test_AccountCache_DoesntCacheErrors(t *testing.T) {
+	api := newAccountCacheTestAPI()
+	api.set("8.50", 500)
+	cache := api.newCache(AccountCacheOptions{})
+
+	if _, err := cache.Get(context.Background()); err == nil || !err.IsUnexpectedError {
+		t.Error(err)
+	}
+
+	api.set("8.50", 200)
+
+	if account, err := cache.Get(context.Background()); err != nil || account == nil {
+		t.Error(err)
+	}
+}
+
+func Test_AccountCache_OnChange(t *testing.T) {
+	api := newAccountCacheTestAPI()
+	changes := make(chan string, 10)
+
+	cache := api.newCache(AccountCacheOptions{
+		TTL: time.Minute,
+		OnChange: func(previous AccountResponse, current AccountResponse) {
+			changes <- previous.Plans[0].CommissionRate + " -> " + current.Plans[0].CommissionRate
+		},
+	})
+
+	cache.Get(context.Background())
+
+	api.advance(time.Minute)
+	cache.Get(context.Background())
+
+	api.set("9.00", 200)
+	api.advance(time.Minute)
+	cache.Get(context.Background())
+
+	// OnChange is called after waiting callers are released, so it may not have happened yet.
+	select {
+	case change := <-changes:
+		if change != "8.50 -> 9.00" {
+			t.Error(change)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnChange wasn't called")
+	}
+
+	select {
+	case change := <-changes:
+		t.Error("unexpected change: " + change)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func Test_AccountCache_Get_Cancelled(t *testing.T) {
+	api := newAccountCacheTestAPI()
+	api.release = make(chan struct{})
+	defer close(api.release)
+
+	cache := api.newCache(AccountCacheOptions{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := cache.Get(ctx); err == nil || !err.IsCancelledError {
+		t.Error(err)
+	}
+}
+
+func Test_AccountCache_Close_StopsFetches(t *testing.T) {
+	api := newAccountCacheTestAPI()
+	api.release = make(chan struct{})
+	defer close(api.release)
+
+	cache := api.newCache(AccountCacheOptions{})
+	result := make(chan *PASDKError, 1)
+
+	go func() {
+		_, err := cache.Get(context.Background())
+		result <- err
+	}()
+
+	api.waitForRequests(t, 1)
+	cache.Close()
+
+	select {
+	case err := <-result:
+		if err == nil || !err.IsCancelledError {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close didn't stop the fetch")
+	}
+
+	if _, err := cache.Get(context.Background()); err == nil || !err.IsCancelledError {
+		t.Error(err)
+	}
+	if api.requestCount() != 1 {
+		t.Error(api.requestCount())
+	}
+}
+
+func Test_AccountCache_IgnoresOutOfOrderChanges(t *testing.T) {
+	var changes []string
+
+	cache := newAccountCacheTestAPI().newCache(AccountCacheOptions{
+		OnChange: func(previous AccountResponse, current AccountResponse) {
+			changes = append(changes, previous.Plans[0].Name+" -> "+current.Plans[0].Name)
+		},
+	})
+
+	fetched := func(number int, name string) *accountFetch {
+		return &accountFetch{number: number, account: &AccountResponse{Plans: []Plan{{ID: 1, Name: name}}}}
+	}
+
+	if cache.finishFetch(fetched(0, "first"), 0) != nil {
+		t.Error()
+	}
+
+	// The second fetch finishes after the third, so only the third counts as a change.
+	third := cache.finishFetch(fetched(2, "third"), 0)
+	second := cache.finishFetch(fetched(1, "second"), 0)
+
+	if third == nil || second != nil {
+		t.Fatal(third, second)
+	}
+	if cache.account.Plans[0].Name != "third" {
+		t.Error(cache.account.Plans[0].Name)
+	}
+
+	fourth := cache.finishFetch(fetched(3, "fourth"), 0)
+
+	// Notifications that arrive late are skipped.
+	cache.notifyChange(*fourth)
+	cache.notifyChange(*third)
+
+	if len(changes) != 1 || changes[0] != "third -> fourth" {
+		t.Error(changes)
+	}
+}
+
+func Test_plansEqual(t *testing.T) {
+	fee := Pounds(1)
+	otherFee := Pounds(2)
+
+	plans := []Plan{{ID: 1, CommissionFixedFee: &fee}}
+
+	if !plansEqual(plans, []Plan{{ID: 1, CommissionFixedFee: &fee}}) {
+		t.Error()
+	}
+	if plansEqual(plans, []Plan{{ID: 1, CommissionFixedFee: &otherFee}}) || plansEqual(plans, []Plan{{ID: 1}}) {
+		t.Error()
+	}
+	if plansEqual(plans, nil) {
+		t.Error()
+	}
+}
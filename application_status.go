@@ -0,0 +1,93 @@
+package pasdk
+
+import "context"
+
+// ApplicationStatus is the status of an application, as returned by the "status" and
+// "capture" endpoints.
+type ApplicationStatus string
+
+const (
+	StatusPending        ApplicationStatus = "pending"         // The application has been created but the customer hasn't started it yet.
+	StatusInProgress     ApplicationStatus = "in_progress"     // The customer is working through the application.
+	StatusPendingCapture ApplicationStatus = "pending_capture" // The application has been approved and is waiting to be captured. This only happens when auto-capture is disabled.
+	StatusCompleted      ApplicationStatus = "completed"       // The application has been completed and the finance facility or payment has been created.
+	StatusDeclined       ApplicationStatus = "declined"        // The application has been declined.
+	StatusExpired        ApplicationStatus = "expired"         // The application expired before it was completed.
+)
+
+// The statuses each status can move to next.
+var statusTransitions = map[ApplicationStatus][]ApplicationStatus{
+	StatusPending:        {StatusInProgress, StatusDeclined, StatusExpired},
+	StatusInProgress:     {StatusPendingCapture, StatusCompleted, StatusDeclined, StatusExpired},
+	StatusPendingCapture: {StatusCompleted, StatusDeclined, StatusExpired},
+	StatusCompleted:      {},
+	StatusDeclined:       {},
+	StatusExpired:        {},
+}
+
+// IsKnown returns true if this is one of the statuses defined by this package.
+func (status ApplicationStatus) IsKnown() bool {
+	_, exists := statusTransitions[status]
+	return exists
+}
+
+// IsTerminal returns true if the application's status can't change any further.
+func (status ApplicationStatus) IsTerminal() bool {
+	return status == StatusCompleted || status == StatusDeclined || status == StatusExpired
+}
+
+// CanTransitionTo returns true if an application can move directly from this status to next.
+func (status ApplicationStatus) CanTransitionTo(next ApplicationStatus) bool {
+	for _, allowed := range statusTransitions[status] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CanUpdateOrderID returns true if an application's order ID can be changed in this status.
+func (status ApplicationStatus) CanUpdateOrderID() bool {
+	return status == StatusCompleted
+}
+
+// CanUpdateAmountOrExpiry returns true if an application's amount and expiry time can be
+// changed in this status.
+func (status ApplicationStatus) CanUpdateAmountOrExpiry() bool {
+	return status == StatusPending || status == StatusInProgress || status == StatusPendingCapture
+}
+
+// CanCapture returns true if an application can be captured in this status.
+func (status ApplicationStatus) CanCapture() bool {
+	return status == StatusPendingCapture
+}
+
+// WithStatusPreflight makes the client check the current status of an application
+// before sending an UpdateRequest or CaptureRequest for it, and fail with a validation
+// error instead of sending the request if the application's status doesn't allow it.
+// This costs an extra call to the "status" endpoint per request.
+func WithStatusPreflight() ClientOption {
+	return func(client *Client) {
+		client.statusPreflight = true
+	}
+}
+
+// Fetches the current status of an application and passes it to check, returning an
+// error if the status couldn't be fetched or check rejected it.
+func (client *Client) checkApplicationStatus(ctx context.Context, applicationToken string,
+	check func(StatusResponse) *PASDKError) *PASDKError {
+	status, err := client.StatusContext(ctx, StatusRequest{ApplicationToken: applicationToken})
+
+	if err != nil {
+		return err.Wrap("failed checking the application's status: ")
+	}
+
+	err = check(*status)
+
+	if err != nil {
+		return err.Wrap("request is invalid for the application's current status: ")
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package pasdk
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_ApplicationStatus_IsTerminal(t *testing.T) {
+	for _, status := range []ApplicationStatus{StatusCompleted, StatusDeclined, StatusExpired} {
+		if !status.IsTerminal() {
+			t.Error(status)
+		}
+	}
+
+	for _, status := range []ApplicationStatus{StatusPending, StatusInProgress, StatusPendingCapture} {
+		if status.IsTerminal() {
+			t.Error(status)
+		}
+	}
+}
+
+func Test_ApplicationStatus_CanTransitionTo(t *testing.T) {
+	if !StatusPending.CanTransitionTo(StatusInProgress) {
+		t.Error()
+	}
+	if !StatusInProgress.CanTransitionTo(StatusPendingCapture) {
+		t.Error()
+	}
+	if !StatusPendingCapture.CanTransitionTo(StatusCompleted) {
+		t.Error()
+	}
+	if StatusCompleted.CanTransitionTo(StatusPending) {
+		t.Error()
+	}
+	if StatusPending.CanTransitionTo(StatusPending) {
+		t.Error()
+	}
+	if ApplicationStatus("unknown").CanTransitionTo(StatusCompleted) {
+		t.Error()
+	}
+
+	// Every status a status can move to must itself be a known status.
+	for status, nextStatuses := range statusTransitions {
+		for _, next := range nextStatuses {
+			if !next.IsKnown() {
+				t.Error(status, next)
+			}
+		}
+	}
+}
+
+func Test_UpdateRequest_CheckAgainstStatus(t *testing.T) {
+	orderID := "neworderid"
+	amount := Money(40000)
+
+	request := UpdateRequest{ApplicationToken: "test", OrderID: &orderID}
+
+	if request.CheckAgainstStatus(StatusResponse{Status: StatusCompleted}) != nil {
+		t.Error()
+	}
+
+	checkFieldErrors(t, request.CheckAgainstStatus(StatusResponse{Status: StatusPending}), []FieldError{
+		{"OrderID", FieldErrorWrongStatus, "OrderID can only be changed when the application's status is \"completed\", " +
+			"but it is \"pending\""},
+	})
+
+	request = UpdateRequest{ApplicationToken: "test", Amount: &amount}
+
+	if request.CheckAgainstStatus(StatusResponse{Status: StatusInProgress, Amount: 50000}) != nil {
+		t.Error()
+	}
+
+	checkFieldErrors(t, request.CheckAgainstStatus(StatusResponse{Status: StatusPending, Amount: 40000}), []FieldError{
+		{"Amount", FieldErrorNotLessThanCurrent, "field Amount must be less than the application's current amount of 40000"},
+	})
+
+	// Every problem is reported, not just the first.
+	request.OrderID = &orderID
+
+	err := request.CheckAgainstStatus(StatusResponse{Status: StatusCompleted, Amount: 30000})
+
+	if err == nil || len(err.FieldErrors) != 2 || err.FieldErrors[0].Field != "Amount" ||
+		err.FieldErrors[0].Code != FieldErrorWrongStatus || err.FieldErrors[1].Code != FieldErrorNotLessThanCurrent {
+		t.Error(err)
+	}
+}
+
+func Test_CaptureRequest_CheckAgainstStatus(t *testing.T) {
+	request := CaptureRequest{ApplicationToken: "test"}
+
+	if request.CheckAgainstStatus(StatusResponse{Status: StatusPendingCapture}) != nil {
+		t.Error()
+	}
+
+	checkFieldErrors(t, request.CheckAgainstStatus(StatusResponse{Status: StatusInProgress}), []FieldError{
+		{"ApplicationToken", FieldErrorWrongStatus, "the application can only be captured when its status is " +
+			"\"pending_capture\", but it is \"in_progress\""},
+	})
+}
+
+func Test_WithStatusPreflight_RejectsInvalidRequestsWithoutSendingThem(t *testing.T) {
+	var endpoints []string
+
+	transport := func(request *http.Request) (*http.Response, error) {
+		endpoints = append(endpoints, getEndpointName(request.URL.Path))
+
+		return newStaticTransport(200, `{"status":"ok","msg":null,"data":{"token":"test","status":"in_progress","amount":50000}}`)(request)
+	}
+
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithTransport(RoundTripperFunc(transport)),
+		WithStatusPreflight())
+
+	response, err := client.Capture(CaptureRequest{ApplicationToken: "test"})
+
+	if response != nil {
+		t.Error()
+	}
+	if err == nil || !err.IsValidationFailedError {
+		t.Error(err)
+		return
+	}
+	if !strings.Contains(err.Error(), `"pending_capture"`) {
+		t.Error(err.Error())
+	}
+	if strings.Join(endpoints, ",") != "status" {
+		t.Error(endpoints)
+	}
+}
@@ -0,0 +1,116 @@
+package pasdk
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchStatusOptions controls how BatchStatus fetches statuses.
+type BatchStatusOptions struct {
+	// Concurrency is the most status requests that are sent at the same time. Values
+	// below 1 are treated as 1. Any rate limit set on the client still applies.
+	Concurrency int
+
+	// OnProgress, if set, is called after each token's status has been fetched or has
+	// failed. It is never called by more than one goroutine at a time.
+	OnProgress func(progress BatchStatusProgress)
+}
+
+// BatchStatusResult is the outcome of fetching the status of a single application.
+type BatchStatusResult struct {
+	ApplicationToken string          // The token the status was fetched for.
+	Response         *StatusResponse // The application's status, or nil if Err is set.
+	Err              *PASDKError     // The error the status request failed with, if any.
+}
+
+// BatchStatusProgress reports how far through a batch BatchStatus is.
+type BatchStatusProgress struct {
+	Result    BatchStatusResult // The result that has just finished.
+	Completed int               // How many tokens have finished, including this one.
+	Total     int               // The number of tokens in the batch.
+}
+
+// BatchStatus fetches the status of several applications using the credentials passed to
+// Initialise. See Client.BatchStatus for details.
+func BatchStatus(ctx context.Context, applicationTokens []string, options BatchStatusOptions) []BatchStatusResult {
+	return defaultClient.BatchStatus(ctx, applicationTokens, options)
+}
+
+// BatchStatus fetches the status of several applications using a pool of up to
+// options.Concurrency workers, and returns a result for each token in the same order as
+// applicationTokens. A request that fails doesn't stop the others; its error is returned
+// in its result instead.
+//
+// If ctx is cancelled, requests that are in flight are abandoned and those that haven't
+// been sent yet aren't sent, and the results for all of them have a cancelled error.
+func (client *Client) BatchStatus(ctx context.Context, applicationTokens []string,
+	options BatchStatusOptions) []BatchStatusResult {
+	results := make([]BatchStatusResult, len(applicationTokens))
+	indexes := make(chan int)
+
+	var group sync.WaitGroup
+	var progressMutex sync.Mutex
+	completed := 0
+
+	finish := func(index int, result BatchStatusResult) {
+		results[index] = result
+
+		progressMutex.Lock()
+		defer progressMutex.Unlock()
+
+		completed++
+
+		if options.OnProgress != nil {
+			options.OnProgress(BatchStatusProgress{Result: result, Completed: completed, Total: len(applicationTokens)})
+		}
+	}
+
+	for i := 0; i < options.workers(len(applicationTokens)); i++ {
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+
+			for index := range indexes {
+				token := applicationTokens[index]
+
+				if ctx.Err() != nil {
+					finish(index, BatchStatusResult{ApplicationToken: token, Err: buildBatchCancelledError(ctx)})
+					continue
+				}
+
+				response, err := client.StatusContext(ctx, StatusRequest{ApplicationToken: token})
+				finish(index, BatchStatusResult{ApplicationToken: token, Response: response, Err: err})
+			}
+		}()
+	}
+
+	for index := range applicationTokens {
+		indexes <- index
+	}
+
+	close(indexes)
+	group.Wait()
+
+	return results
+}
+
+// Returns the number of workers to use for a batch of the given size.
+func (options BatchStatusOptions) workers(batchSize int) int {
+	workers := options.Concurrency
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	if workers > batchSize {
+		workers = batchSize
+	}
+
+	return workers
+}
+
+func buildBatchCancelledError(ctx context.Context) *PASDKError {
+	return buildCancelledError("status request was cancelled before it was sent: " + ctx.Err().Error()).
+		withCause(ctx.Err())
+}
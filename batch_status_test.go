@@ -0,0 +1,133 @@
+package pasdk
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Returns a client whose status responses echo the requested token, failing for tokens
+// starting with "bad", along with a pointer to the most requests seen in flight at once.
+func newBatchStatusTestClient(delay time.Duration) (*Client, *int32) {
+	var inFlight, maxInFlight int32
+
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithTransport(RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+
+			for {
+				seen := atomic.LoadInt32(&maxInFlight)
+
+				if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+					break
+				}
+			}
+
+			select {
+			case <-time.After(delay):
+			case <-request.Context().Done():
+				return nil, request.Context().Err()
+			}
+
+			token := request.URL.Query().Get("token")
+
+			if strings.HasPrefix(token, "bad") {
+				return newStaticTransport(400, `{"status":"error","msg":"Application not found","data":[]}`)(request)
+			}
+
+			return newStaticTransport(200, `{"status":"ok","msg":null,"data":{"token":"`+token+`","status":"pending"}}`)(request)
+		})))
+
+	return client, &maxInFlight
+}
+
+func Test_BatchStatus_ReturnsResultsInOrder(t *testing.T) {
+	client, maxInFlight := newBatchStatusTestClient(5 * time.Millisecond)
+	tokens := []string{"one", "bad-two", "three", "four", "five", "bad-six", "seven"}
+
+	var progress []BatchStatusProgress
+
+	results := client.BatchStatus(context.Background(), tokens, BatchStatusOptions{
+		Concurrency: 3,
+		OnProgress: func(update BatchStatusProgress) {
+			progress = append(progress, update)
+		},
+	})
+
+	if len(results) != len(tokens) {
+		t.Fatal(results)
+	}
+
+	for i, result := range results {
+		if result.ApplicationToken != tokens[i] {
+			t.Error(i, result)
+		}
+
+		if strings.HasPrefix(tokens[i], "bad") {
+			if result.Err == nil || !result.Err.IsRequestRefusedError || result.Response != nil {
+				t.Error(i, result)
+			}
+		} else if result.Err != nil || result.Response.ApplicationToken != tokens[i] {
+			t.Error(i, result)
+		}
+	}
+
+	if *maxInFlight > 3 {
+		t.Error(*maxInFlight)
+	}
+
+	if len(progress) != len(tokens) {
+		t.Fatal(progress)
+	}
+
+	for i, update := range progress {
+		if update.Completed != i+1 || update.Total != len(tokens) {
+			t.Error(update)
+		}
+	}
+}
+
+func Test_BatchStatus_StopsWhenCancelled(t *testing.T) {
+	client, _ := newBatchStatusTestClient(time.Second)
+	tokens := []string{"one", "two", "three", "four"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	results := client.BatchStatus(ctx, tokens, BatchStatusOptions{Concurrency: 2})
+
+	if time.Since(started) > 500*time.Millisecond {
+		t.Error("batch wasn't abandoned")
+	}
+
+	for _, result := range results {
+		if result.Err == nil || !result.Err.IsCancelledError {
+			t.Error(result)
+		}
+	}
+}
+
+func Test_BatchStatus_EmptyBatch(t *testing.T) {
+	client, _ := newBatchStatusTestClient(0)
+
+	if results := client.BatchStatus(context.Background(), nil, BatchStatusOptions{}); len(results) != 0 {
+		t.Error(results)
+	}
+}
+
+func Test_BatchStatusOptions_workers(t *testing.T) {
+	if (BatchStatusOptions{}).workers(10) != 1 {
+		t.Error()
+	}
+	if (BatchStatusOptions{Concurrency: 4}).workers(10) != 4 {
+		t.Error()
+	}
+	if (BatchStatusOptions{Concurrency: 4}).workers(2) != 2 {
+		t.Error()
+	}
+}
@@ -1,11 +1,14 @@
 package pasdk
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // BeginRequest begins the application process. Nullable fields are generally optional.
 type BeginRequest struct {
 	OrderID                  string     // A unique invoice ID or order ID.
-	Amount                   int        // The invoice amount in pence.
+	Amount                   Money      // The invoice amount in pence.
 	CustomerFirstName        string     // The customer's first name.
 	CustomerLastName         string     // The customer's last name.
 	CustomerAddress1         string     // The first line of the customer's address.
@@ -13,9 +16,9 @@ type BeginRequest struct {
 	CustomerAddress3         *string    // The third line of the customer's address.
 	CustomerTown             *string    // The customer's town.
 	CustomerCounty           *string    // The customer's county.
-	CustomerPostcode         string     // The customer's postcode.
+	CustomerPostcode         string     // The customer's UK postcode. This is converted to its canonical form, such as "SW1A 1AA", before it is sent.
 	CustomerEmail            *string    // The customer's email address. This is required if SendEmail is true.
-	CustomerTelephone        *string    // The customer's telephone number. This is required if SendSMS is true.
+	CustomerTelephone        *string    // The customer's telephone number. This is required if SendSMS is true, in which case it must be a UK mobile number and is converted to E.164 format, such as "+447700900123", before it is sent. Otherwise it is sent as given.
 	SendEmail                *bool      // Whether to send the application link to the customer via email. Defaults to false.
 	SendSMS                  *bool      // Whether to send the application link to the customer via SMS. Defaults to false.
 	EnableMultiPlan          *bool      // If true, the customer will see a list of all available payment plans and will be able to select one themselves. Defaults to false.
@@ -37,8 +40,32 @@ type BeginResponse struct {
 	ContinuationURL  string `json:"url"`   // The URL you should direct the customer to so that they can complete the rest of the signup process.
 }
 
-// Fetch executes the request.
-func (request BeginRequest) Fetch() (response *BeginResponse, err *PASDKError) {
+// Fetch executes the request using the credentials passed to Initialise.
+func (request BeginRequest) Fetch() (*BeginResponse, *PASDKError) {
+	return defaultClient.BeginContext(context.Background(), request)
+}
+
+// FetchContext executes the request using the credentials passed to Initialise. The
+// request is abandoned as soon as ctx is cancelled or its deadline passes.
+func (request BeginRequest) FetchContext(ctx context.Context) (*BeginResponse, *PASDKError) {
+	return defaultClient.BeginContext(ctx, request)
+}
+
+// Validate checks the request without sending it, and returns a validation error listing
+// every problem found in FieldErrors, or nil if the request is valid.
+func (request BeginRequest) Validate() *PASDKError {
+	return validateBeginRequest(applyBeginDefaults(request))
+}
+
+// Begin executes the given request using this client's credentials.
+func (client *Client) Begin(request BeginRequest) (*BeginResponse, *PASDKError) {
+	return client.BeginContext(context.Background(), request)
+}
+
+// BeginContext is like Begin, but the request is abandoned as soon as ctx is
+// cancelled or its deadline passes.
+func (client *Client) BeginContext(ctx context.Context, request BeginRequest) (response *BeginResponse, err *PASDKError) {
+	defer client.logFailure(ctx, "begin", &err)
 	defer catchGenericPanic(&response, &err)
 
 	request = applyBeginDefaults(request)
@@ -48,53 +75,61 @@ func (request BeginRequest) Fetch() (response *BeginResponse, err *PASDKError) {
 		return nil, err.Wrap("request is invalid: ")
 	}
 
+	if client.planPreflight && request.PlanID != nil {
+		err = client.checkAccountPlans(ctx, request.CheckAgainstAccount)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Alphabetically sorted.
-	requestParams := []string{
-		"addr1=" + request.CustomerAddress1,
-		"addr2=" + toString(request.CustomerAddress2),
-		"addr3=" + toString(request.CustomerAddress3),
-		"amount=" + toString(request.Amount),
-		"auto_capture=" + toString(request.EnableAutoCapture),
-		"county=" + toString(request.CustomerCounty),
-		"description=" + toString(request.Description),
+	requestParams := []requestParam{
+		{"addr1", request.CustomerAddress1},
+		{"addr2", toString(request.CustomerAddress2)},
+		{"addr3", toString(request.CustomerAddress3)},
+		{"amount", toString(request.Amount)},
+		{"auto_capture", toString(request.EnableAutoCapture)},
+		{"county", toString(request.CustomerCounty)},
+		{"description", toString(request.Description)},
 	}
 	if request.DOB != nil {
-		requestParams = append(requestParams, "dob="+request.DOB.Format("2006-01-02"))
+		requestParams = append(requestParams, requestParam{"dob", request.DOB.Format("2006-01-02")})
 	}
 	requestParams = append(requestParams,
-		"email="+toString(request.CustomerEmail),
-		"expiry="+toString(request.Expiry),
-		"f_name="+request.CustomerFirstName,
-		"failure_url="+toString(request.FailureURL),
-		"multi_plan="+toString(request.EnableMultiPlan),
-		"order_id="+request.OrderID,
-		"plan_id="+toString(request.PlanID),
-		"postcode="+request.CustomerPostcode,
-		"qr_code="+toString(request.ReturnQRCode),
-		"reg_no="+toString(request.VehicleRegistrationPlate),
-		"s_name="+request.CustomerLastName,
-		"send_email="+toString(request.SendEmail),
-		"send_sms="+toString(request.SendSMS),
-		"success_url="+toString(request.SuccessURL),
-		"telephone="+toString(request.CustomerTelephone),
-		"town="+toString(request.CustomerTown),
-		"webhook_url="+toString(request.WebhookURL),
+		requestParam{"email", toString(request.CustomerEmail)},
+		requestParam{"expiry", toString(request.Expiry)},
+		requestParam{"f_name", request.CustomerFirstName},
+		requestParam{"failure_url", toString(request.FailureURL)},
+		requestParam{"multi_plan", toString(request.EnableMultiPlan)},
+		requestParam{"order_id", request.OrderID},
+		requestParam{"plan_id", toString(request.PlanID)},
+		requestParam{"postcode", request.CustomerPostcode},
+		requestParam{"qr_code", toString(request.ReturnQRCode)},
+		requestParam{"reg_no", toString(request.VehicleRegistrationPlate)},
+		requestParam{"s_name", request.CustomerLastName},
+		requestParam{"send_email", toString(request.SendEmail)},
+		requestParam{"send_sms", toString(request.SendSMS)},
+		requestParam{"success_url", toString(request.SuccessURL)},
+		requestParam{"telephone", toString(request.CustomerTelephone)},
+		requestParam{"town", toString(request.CustomerTown)},
+		requestParam{"webhook_url", toString(request.WebhookURL)},
 	)
 
 	requestParams = removeEmptyParams(requestParams)
 
-	signature := generateSignature(requestParams, userCredentials.APISecret)
+	signature := generateSignature(requestParams, client.credentials.APISecret)
 
-	requestParams = append(requestParams, "api_key="+userCredentials.APIKey)
-	requestParams = append(requestParams, "signature="+signature)
+	requestParams = append(requestParams, requestParam{"api_key", client.credentials.APIKey})
+	requestParams = append(requestParams, requestParam{"signature", signature})
 
-	requestURL, err := getRequestURL()
+	requestURL, err := client.getRequestURL()
 
 	if err != nil {
 		return nil, err.Wrap("failed determining request URL: ")
 	}
 
-	response, err = makeAPIPOSTRequest[BeginResponse](requestParams, requestURL+"begin")
+	response, err = makeAPIPOSTRequest[BeginResponse](ctx, client, requestParams, requestURL+"begin")
 
 	if err != nil {
 		return nil, err.Wrap("API request failed: ")
@@ -127,45 +162,38 @@ func applyBeginDefaults(params BeginRequest) BeginRequest {
 		params.EnableAutoCapture = &trueValue
 	}
 
-	return params
-}
+	params.CustomerPostcode = normalisePostcodeIfValid(params.CustomerPostcode)
 
-func validateBeginRequest(request BeginRequest) (err *PASDKError) {
-	if len(request.OrderID) == 0 {
-		return buildValidationFailedError("OrderID cannot be empty")
+	if *params.SendSMS {
+		params.CustomerTelephone = normaliseTelephoneIfValid(params.CustomerTelephone)
 	}
 
-	if request.Amount <= 0 {
-		return buildValidationFailedError("field Amount must be greater than 0")
-	}
+	return params
+}
 
-	if len(request.CustomerFirstName) == 0 {
-		return buildValidationFailedError("CustomerFirstName cannot be empty")
-	}
+func validateBeginRequest(request BeginRequest) (err *PASDKError) {
+	validation := validation{}
 
-	if len(request.CustomerLastName) == 0 {
-		return buildValidationFailedError("CustomerLastName cannot be empty")
-	}
+	validation.required("OrderID", request.OrderID)
 
-	if len(request.CustomerAddress1) == 0 {
-		return buildValidationFailedError("CustomerAddress1 cannot be empty")
+	if request.Amount <= 0 {
+		validation.fail("Amount", FieldErrorNotPositive, "field Amount must be greater than 0")
 	}
 
-	if len(request.CustomerPostcode) == 0 {
-		return buildValidationFailedError("CustomerPostcode cannot be empty")
-	}
+	validation.required("CustomerFirstName", request.CustomerFirstName)
+	validation.required("CustomerLastName", request.CustomerLastName)
+	validation.required("CustomerAddress1", request.CustomerAddress1)
+	validation.check(checkPostcode("CustomerPostcode", request.CustomerPostcode))
 
 	if request.SendEmail != nil &&
 		*request.SendEmail &&
 		(request.CustomerEmail == nil || len(*request.CustomerEmail) == 0) {
-		return buildValidationFailedError("CustomerEmail cannot be empty if SendEmail is true")
+		validation.fail("CustomerEmail", FieldErrorRequired, "CustomerEmail cannot be empty if SendEmail is true")
 	}
 
-	if request.SendSMS != nil &&
-		*request.SendSMS &&
-		(request.CustomerTelephone == nil || len(*request.CustomerTelephone) == 0) {
-		return buildValidationFailedError("CustomerTelephone cannot be empty if SendSMS is true")
+	if request.SendSMS != nil && *request.SendSMS {
+		validation.check(checkSMSTelephone("CustomerTelephone", request.CustomerTelephone))
 	}
 
-	return nil
+	return validation.err()
 }
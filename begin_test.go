@@ -19,7 +19,7 @@ func Test_Begin(t *testing.T) {
 		CustomerFirstName: "Test",
 		CustomerLastName:  "Testington",
 		CustomerAddress1:  "Test House",
-		CustomerPostcode:  "TEST TES",
+		CustomerPostcode:  "TE1 1ST",
 		DOB:               &date,
 	}
 
@@ -41,42 +41,48 @@ func Test_Begin(t *testing.T) {
 func Test_validateBeginRequest(t *testing.T) {
 	request := BeginRequest{}
 
-	if validateBeginRequest(request).Error() != "OrderID cannot be empty" {
+	if validateBeginRequest(request).FieldErrors[0].Message != "OrderID cannot be empty" {
 		t.Error()
 	}
 
 	request.OrderID = "test"
 
-	if validateBeginRequest(request).Error() != "field Amount must be greater than 0" {
+	if validateBeginRequest(request).FieldErrors[0].Message != "field Amount must be greater than 0" {
 		t.Error()
 	}
 
 	request.Amount = 50000
 
-	if validateBeginRequest(request).Error() != "CustomerFirstName cannot be empty" {
+	if validateBeginRequest(request).FieldErrors[0].Message != "CustomerFirstName cannot be empty" {
 		t.Error()
 	}
 
 	request.CustomerFirstName = "test"
 
-	if validateBeginRequest(request).Error() != "CustomerLastName cannot be empty" {
+	if validateBeginRequest(request).FieldErrors[0].Message != "CustomerLastName cannot be empty" {
 		t.Error()
 	}
 
 	request.CustomerLastName = "test"
 
-	if validateBeginRequest(request).Error() != "CustomerAddress1 cannot be empty" {
+	if validateBeginRequest(request).FieldErrors[0].Message != "CustomerAddress1 cannot be empty" {
 		t.Error()
 	}
 
 	request.CustomerAddress1 = "test"
 
-	if validateBeginRequest(request).Error() != "CustomerPostcode cannot be empty" {
+	if validateBeginRequest(request).FieldErrors[0].Message != "CustomerPostcode cannot be empty" {
 		t.Error()
 	}
 
 	request.CustomerPostcode = "test"
 
+	if validateBeginRequest(request).FieldErrors[0].Message != "CustomerPostcode is not a valid UK postcode" {
+		t.Error()
+	}
+
+	request.CustomerPostcode = "te11st"
+
 	if validateBeginRequest(request) != nil {
 		t.Error()
 	}
@@ -86,7 +92,7 @@ func Test_validateBeginRequest(t *testing.T) {
 
 	request.SendEmail = &trueValue
 
-	if validateBeginRequest(request).Error() != "CustomerEmail cannot be empty if SendEmail is true" {
+	if validateBeginRequest(request).FieldErrors[0].Message != "CustomerEmail cannot be empty if SendEmail is true" {
 		t.Error()
 	}
 
@@ -98,12 +104,26 @@ func Test_validateBeginRequest(t *testing.T) {
 
 	request.SendSMS = &trueValue
 
-	if validateBeginRequest(request).Error() != "CustomerTelephone cannot be empty if SendSMS is true" {
+	if validateBeginRequest(request).FieldErrors[0].Message != "CustomerTelephone cannot be empty if SendSMS is true" {
 		t.Error()
 	}
 
 	request.CustomerTelephone = &test
 
+	if validateBeginRequest(request).FieldErrors[0].Message != "CustomerTelephone is not a valid UK telephone number" {
+		t.Error()
+	}
+
+	landline := "020 7946 0000"
+	request.CustomerTelephone = &landline
+
+	if validateBeginRequest(request).FieldErrors[0].Message != "CustomerTelephone must be a UK mobile number if SendSMS is true" {
+		t.Error()
+	}
+
+	mobile := "07700 900123"
+	request.CustomerTelephone = &mobile
+
 	if validateBeginRequest(request) != nil {
 		t.Error()
 	}
@@ -1,5 +1,7 @@
 package pasdk
 
+import "context"
+
 // CaptureRequest allows you to finalise an application that's currently in a "pending_capture" state.
 type CaptureRequest struct {
 	ApplicationToken string // The token you received when calling the "begin" endpoint.
@@ -8,14 +10,38 @@ type CaptureRequest struct {
 // CaptureResponse contains the data returned by a call to the "capture" endpoint. Unlike some other
 // endpoints, "capture" can return a response even when unsuccessful.
 type CaptureResponse struct {
-	ApplicationToken            string  `json:"token"`            // The token representing this application.
-	Status                      string  `json:"status"`           // The status of this application after the application was captured.
-	DepositCaptured             *bool   `json:"deposit_captured"` // Indicates whether the deposit was successfully captured. This is always nil if the application does not include a deposit.
-	DepositCaptureFailureReason *string `json:"deposit_reason"`   // If DepositCaptured is false, this contains the reason for capture failure. This is nil in all other situations.
+	ApplicationToken            string            `json:"token"`            // The token representing this application.
+	Status                      ApplicationStatus `json:"status"`           // The status of this application after the application was captured.
+	DepositCaptured             *bool             `json:"deposit_captured"` // Indicates whether the deposit was successfully captured. This is always nil if the application does not include a deposit.
+	DepositCaptureFailureReason *string           `json:"deposit_reason"`   // If DepositCaptured is false, this contains the reason for capture failure. This is nil in all other situations.
+}
+
+// Fetch executes the request using the credentials passed to Initialise.
+func (request CaptureRequest) Fetch() (*CaptureResponse, *PASDKError) {
+	return defaultClient.CaptureContext(context.Background(), request)
+}
+
+// FetchContext executes the request using the credentials passed to Initialise. The
+// request is abandoned as soon as ctx is cancelled or its deadline passes.
+func (request CaptureRequest) FetchContext(ctx context.Context) (*CaptureResponse, *PASDKError) {
+	return defaultClient.CaptureContext(ctx, request)
+}
+
+// Validate checks the request without sending it, and returns a validation error listing
+// every problem found in FieldErrors, or nil if the request is valid.
+func (request CaptureRequest) Validate() *PASDKError {
+	return validateCaptureRequest(request)
+}
+
+// Capture executes the given request using this client's credentials.
+func (client *Client) Capture(request CaptureRequest) (*CaptureResponse, *PASDKError) {
+	return client.CaptureContext(context.Background(), request)
 }
 
-// Fetch executes the request.
-func (request CaptureRequest) Fetch() (response *CaptureResponse, err *PASDKError) {
+// CaptureContext is like Capture, but the request is abandoned as soon as ctx is
+// cancelled or its deadline passes.
+func (client *Client) CaptureContext(ctx context.Context, request CaptureRequest) (response *CaptureResponse, err *PASDKError) {
+	defer client.logFailure(ctx, "capture", &err)
 	defer catchGenericPanic(&response, &err)
 
 	err = validateCaptureRequest(request)
@@ -24,25 +50,33 @@ func (request CaptureRequest) Fetch() (response *CaptureResponse, err *PASDKErro
 		return nil, err.Wrap("request is invalid: ")
 	}
 
+	if client.statusPreflight {
+		err = client.checkApplicationStatus(ctx, request.ApplicationToken, request.CheckAgainstStatus)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Alphabetically sorted.
-	requestParams := []string{
-		"token=" + toString(request.ApplicationToken),
+	requestParams := []requestParam{
+		{"token", toString(request.ApplicationToken)},
 	}
 
 	requestParams = removeEmptyParams(requestParams)
 
-	signature := generateSignature(requestParams, userCredentials.APISecret)
+	signature := generateSignature(requestParams, client.credentials.APISecret)
 
-	requestParams = append(requestParams, "api_key="+userCredentials.APIKey)
-	requestParams = append(requestParams, "signature="+signature)
+	requestParams = append(requestParams, requestParam{"api_key", client.credentials.APIKey})
+	requestParams = append(requestParams, requestParam{"signature", signature})
 
-	requestURL, err := getRequestURL()
+	requestURL, err := client.getRequestURL()
 
 	if err != nil {
 		return nil, err.Wrap("failed determining request URL: ")
 	}
 
-	response, err = makeAPIPOSTRequest[CaptureResponse](requestParams, requestURL+"capture")
+	response, err = makeAPIPOSTRequest[CaptureResponse](ctx, client, requestParams, requestURL+"capture")
 
 	if err != nil {
 		return nil, err.Wrap("API request failed: ")
@@ -52,9 +86,23 @@ func (request CaptureRequest) Fetch() (response *CaptureResponse, err *PASDKErro
 }
 
 func validateCaptureRequest(request CaptureRequest) (err *PASDKError) {
-	if len(request.ApplicationToken) == 0 {
-		return buildValidationFailedError("ApplicationToken cannot be empty")
+	validation := validation{}
+
+	validation.required("ApplicationToken", request.ApplicationToken)
+
+	return validation.err()
+}
+
+// CheckAgainstStatus returns a validation error if an application with the given status
+// can't be captured, which is the case unless its status is "pending_capture". The problem
+// is reported in FieldErrors against ApplicationToken.
+func (request CaptureRequest) CheckAgainstStatus(status StatusResponse) *PASDKError {
+	validation := validation{}
+
+	if !status.Status.CanCapture() {
+		validation.fail("ApplicationToken", FieldErrorWrongStatus, "the application can only be captured when its "+
+			"status is \"pending_capture\", but it is \""+string(status.Status)+"\"")
 	}
 
-	return nil
+	return validation.err()
 }
@@ -0,0 +1,360 @@
+// Package cassette records the SDK's requests to the Payment Assist API and replays them
+// later, so that tests can run against real API responses without network access.
+//
+// Record interactions once by sending requests through a Recorder, for example against
+// the demo API, and save them to a fixture file:
+//
+//	recorder := cassette.NewRecorder("testdata/begin.json")
+//	client := pasdk.NewClient(credentials, pasdk.WithMiddleware(recorder.Middleware))
+//	...
+//	err := recorder.Save()
+//
+// Then replay them in CI with a Player:
+//
+//	player, err := cassette.Load("testdata/begin.json")
+//	client := pasdk.NewClient(credentials, pasdk.WithTransport(player))
+//
+// The API key and signature are never written to fixture files, and neither are the
+// customer's personal details, such as their name, address, email and telephone number.
+// Invoice files are recorded as their length, and request bodies are parsed as they are
+// sent, so large invoices are never held in memory.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+)
+
+// ErrUnmatchedRequest is returned by a Player when a request doesn't match any of the
+// interactions in its fixture file that haven't already been replayed.
+var ErrUnmatchedRequest = errors.New("cassette: no recorded interaction matches the request")
+
+// The value written to fixture files in place of parameters that are secret or contain
+// the customer's personal details, and of the query string values of URL parameters, as
+// done by pasdk.RedactParam. Sensitive parameters are also ignored when matching requests.
+const redacted = "REDACTED"
+
+// Interaction is a single request to the API and the response it received.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request as it is stored in a fixture file.
+type RecordedRequest struct {
+	Method   string            `json:"method"`
+	Endpoint string            `json:"endpoint"` // The name of the endpoint, such as "begin".
+	Params   map[string]string `json:"params"`   // The request parameters, with secrets and personal details redacted.
+}
+
+// RecordedResponse is a response as it is stored in a fixture file.
+type RecordedResponse struct {
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+type fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder records the requests the SDK sends and the responses it receives. Pass its
+// Middleware method to pasdk.WithMiddleware, then call Save once you have made your requests.
+type Recorder struct {
+	path         string
+	mutex        sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder creates a Recorder that saves its interactions to the file at path.
+func NewRecorder(path string) *Recorder {
+	return &Recorder{path: path}
+}
+
+// Middleware records each request sent through next along with its response. Its
+// signature matches pasdk.Middleware.
+func (recorder *Recorder) Middleware(next http.RoundTripper) http.RoundTripper {
+	return pasdk.RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		finishRecording := startRecording(request)
+
+		response, err := next.RoundTrip(request)
+
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := io.ReadAll(response.Body)
+		response.Body.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		response.Body = io.NopCloser(bytes.NewReader(body))
+		recorded, err := finishRecording()
+
+		if err != nil {
+			return nil, err
+		}
+
+		recorder.mutex.Lock()
+		defer recorder.mutex.Unlock()
+
+		recorder.interactions = append(recorder.interactions, Interaction{
+			Request: recorded,
+			Response: RecordedResponse{
+				StatusCode:  response.StatusCode,
+				ContentType: response.Header.Get("Content-Type"),
+				Body:        string(body),
+			},
+		})
+
+		return response, nil
+	})
+}
+
+// Interactions returns the interactions recorded so far.
+func (recorder *Recorder) Interactions() []Interaction {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	return append([]Interaction{}, recorder.interactions...)
+}
+
+// Save writes the interactions recorded so far to the recorder's fixture file,
+// replacing the file if it already exists.
+func (recorder *Recorder) Save() error {
+	data, err := json.MarshalIndent(fixture{Interactions: recorder.Interactions()}, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(recorder.path, append(data, '\n'), 0644)
+}
+
+// Player replays interactions from a fixture file instead of sending requests to the API.
+// Pass it to pasdk.WithTransport.
+//
+// Each request is answered with the first interaction that hasn't been replayed yet
+// and has the same method, endpoint and parameters, ignoring the API key, signature, the
+// customer's personal details and invoice file data. Each interaction is only replayed once, so a request
+// made several times, such as polling "status", gets the recorded responses in order.
+// A request that doesn't match an interaction fails with ErrUnmatchedRequest.
+type Player struct {
+	mutex         sync.Mutex
+	interactions  []Interaction
+	used          []bool
+	ignoredParams map[string]bool
+}
+
+// Load reads the fixture file at path and returns a Player that replays it. Any
+// ignoredParams are also left out when matching requests, which is useful for
+// parameters that change on every test run, such as a randomly generated "order_id".
+func Load(path string, ignoredParams ...string) (*Player, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var loaded fixture
+
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return nil, errors.New("cassette: " + path + " is not a valid fixture file: " + err.Error())
+	}
+
+	player := &Player{
+		interactions:  loaded.Interactions,
+		used:          make([]bool, len(loaded.Interactions)),
+		ignoredParams: map[string]bool{},
+	}
+
+	for _, param := range ignoredParams {
+		player.ignoredParams[param] = true
+	}
+
+	return player, nil
+}
+
+// RoundTrip answers the request with a recorded response.
+func (player *Player) RoundTrip(request *http.Request) (*http.Response, error) {
+	recorded, err := readRequest(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	player.mutex.Lock()
+	defer player.mutex.Unlock()
+
+	for i, interaction := range player.interactions {
+		if player.used[i] || !player.matches(interaction.Request, recorded) {
+			continue
+		}
+
+		player.used[i] = true
+
+		header := http.Header{}
+
+		if len(interaction.Response.ContentType) > 0 {
+			header.Set("Content-Type", interaction.Response.ContentType)
+		}
+
+		return &http.Response{
+			Status:        http.StatusText(interaction.Response.StatusCode),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       request,
+		}, nil
+	}
+
+	return nil, &unmatchedRequestError{description: player.describe(recorded)}
+}
+
+// Remaining returns the number of interactions that haven't been replayed yet. Tests
+// can check this is 0 to make sure the code under test made every recorded request.
+func (player *Player) Remaining() int {
+	player.mutex.Lock()
+	defer player.mutex.Unlock()
+
+	remaining := 0
+
+	for _, used := range player.used {
+		if !used {
+			remaining++
+		}
+	}
+
+	return remaining
+}
+
+func (player *Player) matches(recorded RecordedRequest, request RecordedRequest) bool {
+	if recorded.Method != request.Method || recorded.Endpoint != request.Endpoint {
+		return false
+	}
+
+	return player.matchedParams(recorded.Params) == player.matchedParams(request.Params)
+}
+
+// Returns the parameters used to match requests, encoded as a string so they can be
+// compared and included in error messages.
+func (player *Player) matchedParams(params map[string]string) string {
+	values := url.Values{}
+
+	for key, value := range params {
+		if !pasdk.IsSensitiveParam(key) && key != fileDataParam && !player.ignoredParams[key] {
+			values.Set(key, value)
+		}
+	}
+
+	return values.Encode()
+}
+
+func (player *Player) describe(request RecordedRequest) string {
+	return request.Method + " " + request.Endpoint + " with params \"" + player.matchedParams(request.Params) + "\""
+}
+
+type unmatchedRequestError struct {
+	description string
+}
+
+func (err *unmatchedRequestError) Error() string {
+	return ErrUnmatchedRequest.Error() + ": " + err.description
+}
+
+func (err *unmatchedRequestError) Is(target error) bool {
+	return target == ErrUnmatchedRequest
+}
+
+// Starts recording a request that is about to be sent. A form-encoded body is parsed as
+// it is sent rather than being read in advance, so it is never held in memory. The
+// returned function waits until the body has been sent, then returns the request's
+// method, endpoint and redacted parameters.
+func startRecording(request *http.Request) func() (RecordedRequest, error) {
+	if !isFormBody(request) {
+		return func() (RecordedRequest, error) {
+			return buildRecordedRequest(request, url.Values{}), nil
+		}
+	}
+
+	body := &parsingBody{body: request.Body, parser: newFormParser(), closed: make(chan struct{})}
+	request.Body = body
+
+	return func() (RecordedRequest, error) {
+		<-body.closed
+
+		params, err := body.parser.finish()
+
+		if err != nil {
+			return RecordedRequest{}, err
+		}
+
+		return buildRecordedRequest(request, params), nil
+	}
+}
+
+// Reads and closes the body of a request that won't be sent, and returns the request's
+// method, endpoint and redacted parameters.
+func readRequest(request *http.Request) (RecordedRequest, error) {
+	params := url.Values{}
+
+	if isFormBody(request) {
+		parser := newFormParser()
+		_, err := io.Copy(parser, request.Body)
+
+		if err != nil {
+			request.Body.Close()
+			return RecordedRequest{}, err
+		}
+
+		params, err = parser.finish()
+
+		if err != nil {
+			request.Body.Close()
+			return RecordedRequest{}, err
+		}
+	}
+
+	if request.Body != nil {
+		request.Body.Close()
+	}
+
+	return buildRecordedRequest(request, params), nil
+}
+
+// Returns the method, endpoint and redacted parameters of a request, given the parameters
+// in its body.
+func buildRecordedRequest(request *http.Request, bodyParams url.Values) RecordedRequest {
+	params := request.URL.Query()
+
+	for key, values := range bodyParams {
+		params[key] = append(params[key], values...)
+	}
+
+	output := RecordedRequest{
+		Method:   request.Method,
+		Endpoint: request.URL.Path[strings.LastIndex(request.URL.Path, "/")+1:],
+		Params:   map[string]string{},
+	}
+
+	for key := range params {
+		output.Params[key] = pasdk.RedactParam(key, params.Get(key), redacted)
+	}
+
+	return output
+}
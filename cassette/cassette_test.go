@@ -0,0 +1,249 @@
+package cassette
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+	"github.com/paymentassist/paymentassist-go/pasdktest"
+)
+
+// The secret in the webhook URL, which must never be written to a fixture.
+const testWebhookSecret = "webhook-s3cret"
+
+func testBeginRequest(orderID string) pasdk.BeginRequest {
+	webhookURL := "https://example.com/webhook?secret=" + testWebhookSecret
+
+	return pasdk.BeginRequest{
+		OrderID:           orderID,
+		Amount:            50000,
+		CustomerFirstName: "Jane",
+		CustomerLastName:  "Secretperson",
+		CustomerAddress1:  "1 Private Road",
+		CustomerPostcode:  "TE1 1ST",
+		WebhookURL:        &webhookURL,
+	}
+}
+
+func recordTestFixture(t *testing.T) (string, *pasdk.BeginResponse, *pasdk.StatusResponse) {
+	server := pasdktest.NewServer()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	recorder := NewRecorder(path)
+	client := server.Client(pasdk.WithMiddleware(recorder.Middleware))
+
+	begin, err := client.Begin(testBeginRequest("cassette-order"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := client.Status(pasdk.StatusRequest{ApplicationToken: begin.ApplicationToken})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if saveErr := recorder.Save(); saveErr != nil {
+		t.Fatal(saveErr)
+	}
+
+	return path, begin, status
+}
+
+func Test_Recorder_RedactsSecretsAndPersonalDetails(t *testing.T) {
+	path, _, _ := recordTestFixture(t)
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{pasdktest.APIKey, "Jane", "Secretperson", "Private Road", "TE1 1ST", testWebhookSecret} {
+		if strings.Contains(string(data), secret) {
+			t.Error("fixture contains " + secret)
+		}
+	}
+
+	if !strings.Contains(string(data), "cassette-order") || !strings.Contains(string(data), "REDACTED") {
+		t.Error(string(data))
+	}
+}
+
+func Test_Player_ReplaysRecordedInteractions(t *testing.T) {
+	path, begin, status := recordTestFixture(t)
+
+	player, err := Load(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := pasdk.NewClient(pasdk.PAAuth{
+		APIKey:    "different_key",
+		APISecret: "different_secret",
+		APIURL:    "https://replay.invalid/",
+	}, pasdk.WithTransport(player))
+
+	request := testBeginRequest("cassette-order")
+	request.CustomerFirstName = "Someone"
+
+	replayedBegin, paErr := client.Begin(request)
+
+	if paErr != nil {
+		t.Fatal(paErr)
+	}
+	if *replayedBegin != *begin {
+		t.Error(replayedBegin)
+	}
+
+	replayedStatus, paErr := client.Status(pasdk.StatusRequest{ApplicationToken: begin.ApplicationToken})
+
+	if paErr != nil {
+		t.Fatal(paErr)
+	}
+	if replayedStatus.Status != status.Status || replayedStatus.Amount != status.Amount {
+		t.Error(replayedStatus)
+	}
+
+	if player.Remaining() != 0 {
+		t.Error(player.Remaining())
+	}
+
+	// Each interaction is only replayed once.
+	_, paErr = client.Begin(request)
+
+	if paErr == nil || !errors.Is(paErr, ErrUnmatchedRequest) {
+		t.Error(paErr)
+	}
+}
+
+func Test_Player_FailsOnUnmatchedRequests(t *testing.T) {
+	path, _, _ := recordTestFixture(t)
+
+	player, err := Load(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := pasdk.NewClient(pasdk.PAAuth{
+		APIKey:    "key",
+		APISecret: "secret",
+		APIURL:    "https://replay.invalid/",
+	}, pasdk.WithTransport(player))
+
+	_, paErr := client.Begin(testBeginRequest("another-order"))
+
+	if paErr == nil || !errors.Is(paErr, ErrUnmatchedRequest) || !strings.Contains(paErr.Error(), "order_id=another-order") {
+		t.Error(paErr)
+	}
+
+	player, _ = Load(path, "order_id")
+	client = pasdk.NewClient(pasdk.PAAuth{
+		APIKey:    "key",
+		APISecret: "secret",
+		APIURL:    "https://replay.invalid/",
+	}, pasdk.WithTransport(player))
+
+	_, paErr = client.Begin(testBeginRequest("another-order"))
+
+	if paErr != nil {
+		t.Error(paErr)
+	}
+}
+
+func Test_Recorder_RecordsInvoiceLengthOnly(t *testing.T) {
+	server := pasdktest.NewServer()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	recorder := NewRecorder(path)
+	client := server.Client(pasdk.WithMiddleware(recorder.Middleware))
+
+	begin, err := client.Begin(testBeginRequest("invoice-order"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := server.Complete(begin.ApplicationToken); err != nil {
+		t.Fatal(err)
+	}
+
+	invoice := "%PDF-1.7\n" + strings.Repeat("Invoice line\n", 1000)
+
+	_, err = client.InvoiceStream(pasdk.InvoiceStreamRequest{
+		ApplicationToken: begin.ApplicationToken,
+		File:             strings.NewReader(invoice),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	interactions := recorder.Interactions()
+	fileData := interactions[len(interactions)-1].Request.Params["filedata"]
+	encodedLength := base64.StdEncoding.EncodedLen(len(invoice))
+
+	if fileData != "["+strconv.Itoa(encodedLength)+" characters]" {
+		t.Error(fileData)
+	}
+
+	if saveErr := recorder.Save(); saveErr != nil {
+		t.Fatal(saveErr)
+	}
+
+	player, loadErr := Load(path, "order_id")
+
+	if loadErr != nil {
+		t.Fatal(loadErr)
+	}
+
+	replayClient := pasdk.NewClient(pasdk.PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://replay.invalid/"},
+		pasdk.WithTransport(player))
+
+	if _, err := replayClient.Begin(testBeginRequest("another-order")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file data is ignored when matching, so a different invoice still matches.
+	_, err = replayClient.InvoiceStream(pasdk.InvoiceStreamRequest{
+		ApplicationToken: begin.ApplicationToken,
+		File:             strings.NewReader("%PDF-1.7\nAnother invoice"),
+	})
+
+	if err != nil || player.Remaining() != 0 {
+		t.Error(err, player.Remaining())
+	}
+}
+
+func Test_formParser(t *testing.T) {
+	parser := newFormParser()
+
+	for _, chunk := range []string{"filed", "ata=ab%2B", "c%3D&file", "type=pdf&to", "ken=a%20b"} {
+		if _, err := parser.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	params, err := parser.finish()
+
+	if err != nil || params.Get("filedata") != "[5 characters]" || params.Get("filetype") != "pdf" || params.Get("token") != "a b" {
+		t.Error(params, err)
+	}
+
+	parser = newFormParser()
+	_, err = parser.Write([]byte("description=" + strings.Repeat("x", maxRecordedBodySize)))
+
+	if err == nil {
+		t.Error()
+	}
+}
@@ -0,0 +1,139 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// The most a request body can contain, apart from invoice file data, before it is too
+// large to record.
+const maxRecordedBodySize = 1024 * 1024
+
+// The parameter holding an invoice file, which is recorded as a placeholder giving its
+// length rather than in full, and ignored when matching requests.
+const fileDataParam = "filedata"
+
+// Parses a form-encoded request body as it is written to it. Only the length of the
+// invoice file data is kept, so that large invoices never have to be held in memory.
+type formParser struct {
+	params         url.Values
+	pair           []byte // The parameter being parsed, unless it is the file data.
+	inFileData     bool   // Whether the parameter being parsed is the file data.
+	fileDataLength int    // The number of characters of file data, once unescaped.
+	escapeDigits   int    // The number of hex digits still to come in a %-escape in the file data.
+	size           int
+	err            error
+}
+
+func newFormParser() *formParser {
+	return &formParser{params: url.Values{}}
+}
+
+func (parser *formParser) Write(data []byte) (int, error) {
+	if parser.err != nil {
+		return 0, parser.err
+	}
+
+	for _, character := range data {
+		switch {
+		case character == '&':
+			parser.finishParam()
+		case parser.inFileData && parser.escapeDigits > 0:
+			parser.escapeDigits--
+		case parser.inFileData:
+			parser.fileDataLength++
+
+			if character == '%' {
+				parser.escapeDigits = 2
+			}
+		default:
+			parser.pair = append(parser.pair, character)
+			parser.size++
+
+			if character == '=' && string(parser.pair) == fileDataParam+"=" {
+				parser.inFileData = true
+				parser.size -= len(parser.pair)
+				parser.pair = parser.pair[:0]
+			}
+		}
+
+		if parser.err == nil && parser.size > maxRecordedBodySize {
+			parser.err = errors.New("cassette: request body is too large to record")
+		}
+
+		if parser.err != nil {
+			return 0, parser.err
+		}
+	}
+
+	return len(data), nil
+}
+
+// Records the parameter that has just been parsed.
+func (parser *formParser) finishParam() {
+	if parser.inFileData {
+		parser.params.Add(fileDataParam, "["+strconv.Itoa(parser.fileDataLength)+" characters]")
+		parser.inFileData = false
+		parser.fileDataLength = 0
+	} else if len(parser.pair) > 0 {
+		values, err := url.ParseQuery(string(parser.pair))
+
+		if err != nil {
+			parser.err = errors.New("cassette: request body is not form data: " + err.Error())
+		}
+
+		for key, value := range values {
+			parser.params[key] = append(parser.params[key], value...)
+		}
+	}
+
+	parser.pair = parser.pair[:0]
+}
+
+// Returns the parameters parsed once the whole body has been written.
+func (parser *formParser) finish() (url.Values, error) {
+	if parser.err == nil {
+		parser.finishParam()
+	}
+
+	return parser.params, parser.err
+}
+
+// Wraps a request body so that it is parsed as it is read by whatever sends the request.
+type parsingBody struct {
+	body   io.ReadCloser
+	parser *formParser
+	once   sync.Once
+	closed chan struct{} // Closed once the body has been closed.
+}
+
+func (body *parsingBody) Read(data []byte) (int, error) {
+	size, err := body.body.Read(data)
+
+	if size > 0 {
+		if _, parseErr := body.parser.Write(data[:size]); parseErr != nil {
+			return 0, parseErr
+		}
+	}
+
+	return size, err
+}
+
+func (body *parsingBody) Close() error {
+	body.once.Do(func() { close(body.closed) })
+
+	return body.body.Close()
+}
+
+// Returns true if the request's body is form data, which is the only kind of body the
+// API accepts and so the only kind recorded.
+func isFormBody(request *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+
+	return request.Body != nil && request.Body != http.NoBody && mediaType == "application/x-www-form-urlencoded"
+}
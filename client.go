@@ -0,0 +1,178 @@
+package pasdk
+
+import (
+	"net/http"
+	"time"
+)
+
+// Client sends requests to the Payment Assist API using its own credentials and
+// configuration. Several clients can be used side by side, for example to talk to
+// more than one merchant account, or to the demo and production APIs at the same time.
+type Client struct {
+	credentials PAAuth
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
+	pollOptions PollOptions
+
+	statusPreflight bool
+	planPreflight   bool
+	accountCache    *AccountCache
+	maxInvoiceSize  int64
+	tracer          Tracer
+	logger          Logger
+
+	rateLimiter          *rateLimiter
+	endpointRateLimiters map[string]*rateLimiter
+
+	// These are only used while the client is being built.
+	timeout    *time.Duration
+	transport  http.RoundTripper
+	middleware []Middleware
+}
+
+// ClientOption configures optional behaviour of a Client.
+type ClientOption func(client *Client)
+
+// Middleware wraps an http.RoundTripper with additional behaviour, such as tracing,
+// metrics or extra headers. It should return a RoundTripper that calls next to
+// actually send the request.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc allows an ordinary function to be used as an http.RoundTripper,
+// which is convenient when writing Middleware.
+type RoundTripperFunc func(request *http.Request) (*http.Response, error)
+
+// RoundTrip calls function(request).
+func (function RoundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return function(request)
+}
+
+// WithTimeout sets the maximum amount of time a single request to the API may take.
+// The default is 30 seconds, or the timeout of the client passed to WithHTTPClient.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(client *Client) {
+		client.timeout = &timeout
+	}
+}
+
+// WithHTTPClient makes the client send requests using a copy of the given http.Client,
+// for example one that has been configured to use an egress proxy or custom TLS roots.
+// If httpClient is nil, the default http.Client is used, as if the option wasn't given.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(client *Client) {
+		if httpClient == nil {
+			client.httpClient = newDefaultHTTPClient()
+			return
+		}
+
+		client.httpClient = httpClient
+	}
+}
+
+// WithTransport makes the client send requests through the given http.RoundTripper,
+// replacing the transport of the underlying http.Client.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(client *Client) {
+		client.transport = transport
+	}
+}
+
+// WithMiddleware wraps the client's transport with the given middleware. Middleware
+// is applied in the order given, so the first middleware sees each request first
+// and each response last. This option can be passed more than once.
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(client *Client) {
+		client.middleware = append(client.middleware, middleware...)
+	}
+}
+
+// WithMaxInvoiceSize sets the largest invoice file, in bytes, that the client will upload.
+// Larger invoices fail validation instead of being sent. The default is DefaultMaxInvoiceSize,
+// which is also used if bytes is 0 or less.
+func WithMaxInvoiceSize(bytes int64) ClientOption {
+	return func(client *Client) {
+		if bytes <= 0 {
+			bytes = DefaultMaxInvoiceSize
+		}
+
+		client.maxInvoiceSize = bytes
+	}
+}
+
+// NewClient creates a new client that sends requests using the given credentials.
+func NewClient(credentials PAAuth, options ...ClientOption) *Client {
+	client := &Client{
+		credentials:    credentials,
+		httpClient:     newDefaultHTTPClient(),
+		pollOptions:    DefaultPollOptions(),
+		maxInvoiceSize: DefaultMaxInvoiceSize,
+	}
+
+	for _, option := range options {
+		option(client)
+	}
+
+	client.httpClient = client.buildHTTPClient()
+
+	if client.planPreflight && client.accountCache == nil {
+		client.accountCache = client.NewAccountCache(AccountCacheOptions{})
+	}
+
+	client.timeout = nil
+	client.transport = nil
+	client.middleware = nil
+
+	return client
+}
+
+// Returns a copy of the configured http.Client with the configured timeout,
+// transport and middleware applied.
+func (client *Client) buildHTTPClient() *http.Client {
+	httpClient := *client.httpClient
+
+	if client.timeout != nil {
+		httpClient.Timeout = *client.timeout
+	}
+
+	transport := client.transport
+
+	if transport == nil {
+		transport = httpClient.Transport
+	}
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	for i := len(client.middleware) - 1; i >= 0; i-- {
+		transport = client.middleware[i](transport)
+	}
+
+	httpClient.Transport = transport
+
+	return &httpClient
+}
+
+// Returns the http.Client used unless WithHTTPClient is given.
+func newDefaultHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: 30 * time.Second,
+	}
+}
+
+// The client used by the Fetch methods on each request type. This is replaced
+// whenever Initialise is called.
+var defaultClient = NewClient(PAAuth{})
+
+// DefaultClient returns the client used by the Fetch methods, as configured by
+// the most recent call to Initialise.
+func DefaultClient() *Client {
+	return defaultClient
+}
+
+// Returns true if requests sent by this client should be answered with canned
+// responses rather than being sent to the API. This only happens during unit tests
+// for clients that haven't been given an API URL.
+func (client *Client) usesMockAPI() bool {
+	return testsAreRunning && !shouldRunIntegrationTests() && len(client.credentials.APIURL) == 0
+}
@@ -0,0 +1,237 @@
+package pasdk
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Returns a TLS test server that replies to every request using the given handler,
+// along with a client that trusts the server's certificate.
+func newTestServerClient(t *testing.T, credentials PAAuth, handler http.HandlerFunc) (*httptest.Server, *Client) {
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	credentials.APIURL = server.URL
+
+	client := NewClient(credentials, WithHTTPClient(server.Client()))
+
+	return server, client
+}
+
+func Test_NewClient_UsesOwnCredentials(t *testing.T) {
+	if shouldRunIntegrationTests() {
+		return
+	}
+
+	var firstAPIKey, secondAPIKey string
+
+	_, firstClient := newTestServerClient(t, PAAuth{APIKey: "first", APISecret: "secret"},
+		func(writer http.ResponseWriter, request *http.Request) {
+			firstAPIKey = request.URL.Query().Get("api_key")
+			writer.Write([]byte(`{"status":"ok","msg":null,"data":{"legal_name":"First","display_name":"First","plans":[]}}`))
+		})
+
+	_, secondClient := newTestServerClient(t, PAAuth{APIKey: "second", APISecret: "secret"},
+		func(writer http.ResponseWriter, request *http.Request) {
+			secondAPIKey = request.URL.Query().Get("api_key")
+			writer.Write([]byte(`{"status":"ok","msg":null,"data":{"legal_name":"Second","display_name":"Second","plans":[]}}`))
+		})
+
+	firstResponse, err := firstClient.Account(AccountRequest{})
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	secondResponse, err := secondClient.Account(AccountRequest{})
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if firstResponse.LegalName != "First" || firstAPIKey != "first" {
+		t.Error()
+	}
+	if secondResponse.LegalName != "Second" || secondAPIKey != "second" {
+		t.Error()
+	}
+}
+
+func Test_Initialise_ReplacesDefaultClient(t *testing.T) {
+	currentClient := defaultClient
+
+	defer func() {
+		defaultClient = currentClient
+	}()
+
+	Initialise(PAAuth{APIKey: "key", APISecret: "secret"})
+
+	if DefaultClient() == currentClient {
+		t.Error()
+	}
+	if DefaultClient().credentials.APIKey != "key" {
+		t.Error()
+	}
+}
+
+func Test_FetchContext_ReturnsCancelledError_WhenContextAlreadyCancelled(t *testing.T) {
+	if shouldRunIntegrationTests() {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	response, err := StatusRequest{ApplicationToken: "test"}.FetchContext(ctx)
+
+	if response != nil {
+		t.Error()
+	}
+	if err == nil || !err.IsCancelledError {
+		t.Error(err)
+		return
+	}
+	if err.GetErrorType() != "CancelledError" {
+		t.Error(err.GetErrorType())
+	}
+}
+
+func Test_ClientContext_StopsWaiting_WhenContextCancelled(t *testing.T) {
+	if shouldRunIntegrationTests() {
+		return
+	}
+
+	released := make(chan struct{})
+	defer close(released)
+
+	_, client := newTestServerClient(t, PAAuth{APIKey: "key", APISecret: "secret"},
+		func(writer http.ResponseWriter, request *http.Request) {
+			select {
+			case <-released:
+			case <-request.Context().Done():
+			}
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+
+	_, err := client.BeginContext(ctx, BeginRequest{
+		OrderID:           "111",
+		Amount:            50000,
+		CustomerFirstName: "Test",
+		CustomerLastName:  "Testington",
+		CustomerAddress1:  "Test House",
+		CustomerPostcode:  "TE1 1ST",
+	})
+
+	if err == nil || !err.IsCancelledError {
+		t.Error(err)
+	}
+	if time.Since(started) > 5*time.Second {
+		t.Error("request was not abandoned when the context expired")
+	}
+}
+
+// Returns a transport that answers every request with the given status code and body,
+// without sending anything over the network.
+func newStaticTransport(statusCode int, body string) RoundTripperFunc {
+	return func(request *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: statusCode,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    request,
+		}, nil
+	}
+}
+
+func Test_WithTransport_IsUsedToSendRequests(t *testing.T) {
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithTransport(newStaticTransport(200, `{"status":"ok","msg":null,"data":{"approved":true}}`)))
+
+	response, err := client.Preapproval(PreapprovalRequest{
+		CustomerFirstName: "Test",
+		CustomerLastName:  "Testington",
+		CustomerAddress1:  "Test House",
+		CustomerPostcode:  "TE1 1ST",
+	})
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !response.Approved {
+		t.Error()
+	}
+}
+
+func Test_WithMiddleware_AppliesMiddlewareInOrder(t *testing.T) {
+	var calls []string
+
+	buildMiddleware := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				response, err := next.RoundTrip(request)
+				calls = append(calls, name+" after")
+
+				return response, err
+			})
+		}
+	}
+
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithMiddleware(buildMiddleware("first")),
+		WithMiddleware(buildMiddleware("second")),
+		WithTransport(newStaticTransport(200, `{"status":"ok","msg":null,"data":{"approved":true}}`)))
+
+	_, err := client.Preapproval(PreapprovalRequest{
+		CustomerFirstName: "Test",
+		CustomerLastName:  "Testington",
+		CustomerAddress1:  "Test House",
+		CustomerPostcode:  "TE1 1ST",
+	})
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if strings.Join(calls, ",") != "first before,second before,second after,first after" {
+		t.Error(calls)
+	}
+}
+
+func Test_NewClient_DoesntModifyHTTPClient(t *testing.T) {
+	httpClient := &http.Client{Timeout: time.Minute}
+
+	client := NewClient(PAAuth{},
+		WithHTTPClient(httpClient),
+		WithTimeout(time.Second),
+		WithTransport(newStaticTransport(200, "")))
+
+	if httpClient.Timeout != time.Minute || httpClient.Transport != nil {
+		t.Error()
+	}
+	if client.httpClient.Timeout != time.Second {
+		t.Error()
+	}
+}
+
+func Test_WithHTTPClient_UsesDefault_WhenNil(t *testing.T) {
+	client := NewClient(PAAuth{}, WithHTTPClient(nil))
+
+	if client.httpClient == nil || client.httpClient.Timeout != 30*time.Second ||
+		client.httpClient.Transport != http.DefaultTransport {
+		t.Error(client.httpClient)
+	}
+}
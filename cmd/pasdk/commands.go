@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"time"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+)
+
+type sendFunc = func(ctx context.Context, client *pasdk.Client) (interface{}, *pasdk.PASDKError)
+
+// A flag holding an amount of money, given in pounds such as "12.34" or "£1,250".
+type moneyFlag struct {
+	value pasdk.Money
+}
+
+func (flag *moneyFlag) String() string {
+	if flag == nil || flag.value == 0 {
+		return ""
+	}
+
+	return flag.value.String()
+}
+
+func (flag *moneyFlag) Set(text string) error {
+	value, err := pasdk.ParseMoney(text)
+
+	if err != nil {
+		return err
+	}
+
+	flag.value = value
+
+	return nil
+}
+
+// A flag holding a date in the format YYYY-MM-DD.
+type dateFlag struct {
+	value *time.Time
+}
+
+func (flag *dateFlag) String() string {
+	if flag == nil || flag.value == nil {
+		return ""
+	}
+
+	return flag.value.Format("2006-01-02")
+}
+
+func (flag *dateFlag) Set(text string) error {
+	value, err := time.Parse("2006-01-02", text)
+
+	if err != nil {
+		return errors.New("dates must be in the format YYYY-MM-DD")
+	}
+
+	flag.value = &value
+
+	return nil
+}
+
+// Returns the names of the flags that were given on the command line.
+func setFlags(flags *flag.FlagSet) map[string]bool {
+	output := map[string]bool{}
+
+	flags.Visit(func(setFlag *flag.Flag) {
+		output[setFlag.Name] = true
+	})
+
+	return output
+}
+
+// Returns a pointer to value if the named flag was given, otherwise nil. This is used
+// to fill in the optional fields of requests.
+func optional[T interface{}](flags *flag.FlagSet, name string, value T) *T {
+	if !setFlags(flags)[name] {
+		return nil
+	}
+
+	return &value
+}
+
+func registerAccount(flags *flag.FlagSet) sendFunc {
+	return func(ctx context.Context, client *pasdk.Client) (interface{}, *pasdk.PASDKError) {
+		return client.AccountContext(ctx, pasdk.AccountRequest{})
+	}
+}
+
+func registerPlan(flags *flag.FlagSet) sendFunc {
+	var amount moneyFlag
+	flags.Var(&amount, "amount", "The amount in pounds, such as 1250.00.")
+	planID := flags.Int("plan-id", 0, "The plan ID. The account's default plan is used if this isn't given.")
+
+	return func(ctx context.Context, client *pasdk.Client) (interface{}, *pasdk.PASDKError) {
+		return client.PlanContext(ctx, pasdk.PlanRequest{
+			Amount: amount.value,
+			PlanID: optional(flags, "plan-id", *planID),
+		})
+	}
+}
+
+func registerPreapproval(flags *flag.FlagSet) sendFunc {
+	firstName := flags.String("first-name", "", "The customer's first name.")
+	lastName := flags.String("last-name", "", "The customer's last name.")
+	address1 := flags.String("address1", "", "The first line of the customer's address.")
+	postcode := flags.String("postcode", "", "The customer's postcode.")
+
+	return func(ctx context.Context, client *pasdk.Client) (interface{}, *pasdk.PASDKError) {
+		return client.PreapprovalContext(ctx, pasdk.PreapprovalRequest{
+			CustomerFirstName: *firstName,
+			CustomerLastName:  *lastName,
+			CustomerAddress1:  *address1,
+			CustomerPostcode:  *postcode,
+		})
+	}
+}
+
+func registerBegin(flags *flag.FlagSet) sendFunc {
+	orderID := flags.String("order-id", "", "A unique invoice ID or order ID.")
+	var amount moneyFlag
+	flags.Var(&amount, "amount", "The invoice amount in pounds, such as 1250.00.")
+	firstName := flags.String("first-name", "", "The customer's first name.")
+	lastName := flags.String("last-name", "", "The customer's last name.")
+	address1 := flags.String("address1", "", "The first line of the customer's address.")
+	address2 := flags.String("address2", "", "The second line of the customer's address.")
+	address3 := flags.String("address3", "", "The third line of the customer's address.")
+	town := flags.String("town", "", "The customer's town.")
+	county := flags.String("county", "", "The customer's county.")
+	postcode := flags.String("postcode", "", "The customer's postcode.")
+	email := flags.String("email", "", "The customer's email address.")
+	telephone := flags.String("telephone", "", "The customer's telephone number.")
+	sendEmail := flags.Bool("send-email", false, "Send the application link to the customer via email.")
+	sendSMS := flags.Bool("send-sms", false, "Send the application link to the customer via SMS.")
+	multiPlan := flags.Bool("multi-plan", false, "Let the customer choose from all available plans.")
+	qrCode := flags.Bool("qr-code", false, "Return a QR code the customer can scan to continue the application.")
+	autoCapture := flags.Bool("auto-capture", true, "Capture the application automatically once it is approved.")
+	failureURL := flags.String("failure-url", "", "Where to send the customer if the application is declined.")
+	successURL := flags.String("success-url", "", "Where to send the customer if the application is approved.")
+	webhookURL := flags.String("webhook-url", "", "A URL to send webhooks to.")
+	planID := flags.Int("plan-id", 0, "The ID of the application's plan type.")
+	registration := flags.String("reg-no", "", "The vehicle's registration plate, where relevant.")
+	description := flags.String("description", "", "A description of the services or goods being sold.")
+	expiry := flags.Int("expiry", 0, "The number of seconds before the application expires.")
+	var dob dateFlag
+	flags.Var(&dob, "dob", "The customer's date of birth, in the format YYYY-MM-DD.")
+
+	return func(ctx context.Context, client *pasdk.Client) (interface{}, *pasdk.PASDKError) {
+		return client.BeginContext(ctx, pasdk.BeginRequest{
+			OrderID:                  *orderID,
+			Amount:                   amount.value,
+			CustomerFirstName:        *firstName,
+			CustomerLastName:         *lastName,
+			CustomerAddress1:         *address1,
+			CustomerAddress2:         optional(flags, "address2", *address2),
+			CustomerAddress3:         optional(flags, "address3", *address3),
+			CustomerTown:             optional(flags, "town", *town),
+			CustomerCounty:           optional(flags, "county", *county),
+			CustomerPostcode:         *postcode,
+			CustomerEmail:            optional(flags, "email", *email),
+			CustomerTelephone:        optional(flags, "telephone", *telephone),
+			SendEmail:                optional(flags, "send-email", *sendEmail),
+			SendSMS:                  optional(flags, "send-sms", *sendSMS),
+			EnableMultiPlan:          optional(flags, "multi-plan", *multiPlan),
+			ReturnQRCode:             optional(flags, "qr-code", *qrCode),
+			EnableAutoCapture:        optional(flags, "auto-capture", *autoCapture),
+			FailureURL:               optional(flags, "failure-url", *failureURL),
+			SuccessURL:               optional(flags, "success-url", *successURL),
+			WebhookURL:               optional(flags, "webhook-url", *webhookURL),
+			PlanID:                   optional(flags, "plan-id", *planID),
+			VehicleRegistrationPlate: optional(flags, "reg-no", *registration),
+			Description:              optional(flags, "description", *description),
+			Expiry:                   optional(flags, "expiry", *expiry),
+			DOB:                      dob.value,
+		})
+	}
+}
+
+func registerStatus(flags *flag.FlagSet) sendFunc {
+	token := flags.String("token", "", "The application's token.")
+
+	return func(ctx context.Context, client *pasdk.Client) (interface{}, *pasdk.PASDKError) {
+		return client.StatusContext(ctx, pasdk.StatusRequest{ApplicationToken: *token})
+	}
+}
+
+func registerUpdate(flags *flag.FlagSet) sendFunc {
+	token := flags.String("token", "", "The application's token.")
+	orderID := flags.String("order-id", "", "The new order ID.")
+	expiry := flags.Int("expiry", 0, "The new expiry time in seconds from now. 0 expires the application immediately.")
+	var amount moneyFlag
+	flags.Var(&amount, "amount", "The new amount in pounds, such as 1250.00.")
+
+	return func(ctx context.Context, client *pasdk.Client) (interface{}, *pasdk.PASDKError) {
+		return client.UpdateContext(ctx, pasdk.UpdateRequest{
+			ApplicationToken: *token,
+			OrderID:          optional(flags, "order-id", *orderID),
+			ExpiresIn:        optional(flags, "expiry", *expiry),
+			Amount:           optional(flags, "amount", amount.value),
+		})
+	}
+}
+
+func registerCapture(flags *flag.FlagSet) sendFunc {
+	token := flags.String("token", "", "The application's token.")
+
+	return func(ctx context.Context, client *pasdk.Client) (interface{}, *pasdk.PASDKError) {
+		return client.CaptureContext(ctx, pasdk.CaptureRequest{ApplicationToken: *token})
+	}
+}
+
+func registerInvoice(flags *flag.FlagSet) sendFunc {
+	token := flags.String("token", "", "The application's token.")
+	file := flags.String("file", "", "The invoice file to upload, or - to read it from standard input.")
+	fileType := flags.String("type", "", "The file type, such as pdf. Detected from the file's contents if not given.")
+
+	return func(ctx context.Context, client *pasdk.Client) (interface{}, *pasdk.PASDKError) {
+		request := pasdk.InvoiceStreamRequest{
+			ApplicationToken: *token,
+			FileType:         pasdk.InvoiceFileType(*fileType),
+			FilePath:         *file,
+		}
+
+		if *file == "-" {
+			request.FilePath = ""
+			request.File = os.Stdin
+		}
+
+		return client.InvoiceStreamContext(ctx, request)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+)
+
+// The contents of a config file.
+type config struct {
+	APIKey    string `json:"api_key"`
+	APISecret string `json:"api_secret"`
+	APIURL    string `json:"api_url"`
+}
+
+// Returns the credentials from the config file at configPath, if there is one, with
+// any that are set in the environment taking precedence.
+func loadCredentials(configPath string, getenv func(string) string) (pasdk.PAAuth, error) {
+	var loaded config
+
+	if len(configPath) > 0 {
+		data, err := os.ReadFile(configPath)
+
+		if err != nil {
+			return pasdk.PAAuth{}, errors.New("failed reading config file: " + err.Error())
+		}
+
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return pasdk.PAAuth{}, errors.New("config file " + configPath + " is not valid JSON: " + err.Error())
+		}
+	}
+
+	credentials := pasdk.PAAuth{
+		APIKey:    loaded.APIKey,
+		APISecret: loaded.APISecret,
+		APIURL:    loaded.APIURL,
+	}
+
+	if value := getenv("PASDK_API_KEY"); len(value) > 0 {
+		credentials.APIKey = value
+	}
+
+	if value := getenv("PASDK_API_SECRET"); len(value) > 0 {
+		credentials.APISecret = value
+	}
+
+	if value := getenv("PASDK_API_URL"); len(value) > 0 {
+		credentials.APIURL = value
+	}
+
+	return credentials, nil
+}
@@ -0,0 +1,175 @@
+// Command pasdk sends requests to the Payment Assist API from the command line, which is
+// useful for checking on an application or uploading an invoice by hand.
+//
+// Usage:
+//
+//	pasdk <command> [flags]
+//
+// The commands are account, plan, preapproval, begin, status, update, capture and
+// invoice. Run "pasdk <command> -h" to see the flags each command accepts.
+//
+// Credentials are read from the PASDK_API_KEY, PASDK_API_SECRET and PASDK_API_URL
+// environment variables, or from a JSON config file given with -config or the
+// PASDK_CONFIG environment variable, such as:
+//
+//	{"api_key": "...", "api_secret": "...", "api_url": "https://api.demo.payassi.st/"}
+//
+// Environment variables take precedence over the config file.
+//
+// Responses are printed as a human-readable table, or as JSON with -output json.
+//
+// The exit code is 0 on success, 2 if the command line was invalid, 3 if the request
+// failed validation, 4 if the API refused the request and 1 for any other error.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+)
+
+const (
+	exitOK               = 0
+	exitUnexpected       = 1
+	exitUsage            = 2
+	exitValidationFailed = 3
+	exitRequestRefused   = 4
+)
+
+// A subcommand. register adds the command's flags to the flag set and returns a
+// function that sends the request once the flags have been parsed.
+type command struct {
+	description string
+	register    func(flags *flag.FlagSet) sendFunc
+}
+
+var commands = map[string]command{
+	"account":     {"Show the merchant's account details and plans.", registerAccount},
+	"plan":        {"Show the repayment schedule for an amount.", registerPlan},
+	"preapproval": {"Check whether a customer would pass pre-approval.", registerPreapproval},
+	"begin":       {"Begin an application.", registerBegin},
+	"status":      {"Show the status of an application.", registerStatus},
+	"update":      {"Update an application.", registerUpdate},
+	"capture":     {"Capture an application that is awaiting capture.", registerCapture},
+	"invoice":     {"Upload an invoice for a completed application.", registerInvoice},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	exitCode := run(ctx, os.Args[1:], os.Getenv, os.Stdout, os.Stderr)
+	stop()
+
+	os.Exit(exitCode)
+}
+
+// Runs the command given by args and returns the exit code. Any options are passed
+// on to the client, which lets tests point it at a fake server.
+func run(ctx context.Context, args []string, getenv func(string) string, stdout io.Writer, stderr io.Writer,
+	options ...pasdk.ClientOption) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	command, exists := commands[args[0]]
+
+	if !exists {
+		fmt.Fprintln(stderr, "pasdk: unknown command \""+args[0]+"\"")
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	flags := flag.NewFlagSet("pasdk "+args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	configPath := flags.String("config", getenv("PASDK_CONFIG"), "Path to a JSON config file containing your credentials.")
+	output := flags.String("output", "table", "The output format, either \"table\" or \"json\".")
+	send := command.register(flags)
+
+	if err := flags.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+
+		return exitUsage
+	}
+
+	if flags.NArg() > 0 {
+		fmt.Fprintln(stderr, "pasdk: unexpected argument \""+flags.Arg(0)+"\"")
+		return exitUsage
+	}
+
+	if *output != "table" && *output != "json" {
+		fmt.Fprintln(stderr, "pasdk: -output must be \"table\" or \"json\"")
+		return exitUsage
+	}
+
+	credentials, err := loadCredentials(*configPath, getenv)
+
+	if err != nil {
+		fmt.Fprintln(stderr, "pasdk: "+err.Error())
+		return exitUsage
+	}
+
+	response, paErr := send(ctx, pasdk.NewClient(credentials, options...))
+
+	if paErr != nil {
+		fmt.Fprintln(stderr, "pasdk: "+paErr.Error())
+		return exitCodeFor(paErr)
+	}
+
+	if *output == "json" {
+		err = printJSON(stdout, response)
+	} else {
+		err = printTable(stdout, response)
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, "pasdk: failed printing response: "+err.Error())
+		return exitUnexpected
+	}
+
+	return exitOK
+}
+
+// Returns the exit code for a failed request.
+func exitCodeFor(err *pasdk.PASDKError) int {
+	if err.IsValidationFailedError {
+		return exitValidationFailed
+	}
+
+	if err.IsRequestRefusedError {
+		return exitRequestRefused
+	}
+
+	return exitUnexpected
+}
+
+func printUsage(output io.Writer) {
+	names := make([]string, 0, len(commands))
+
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintln(output, "Usage: pasdk <command> [flags]")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Commands:")
+
+	for _, name := range names {
+		fmt.Fprintln(output, "  "+name+strings.Repeat(" ", 13-len(name))+commands[name].description)
+	}
+
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Run \"pasdk <command> -h\" to see the flags for a command.")
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+	"github.com/paymentassist/paymentassist-go/pasdktest"
+)
+
+// Runs the command against server, returning the exit code and what was printed.
+func runTestCommand(server *pasdktest.Server, environment map[string]string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+
+	getenv := func(name string) string {
+		return environment[name]
+	}
+
+	exitCode := run(context.Background(), args, getenv, &stdout, &stderr, pasdk.WithHTTPClient(server.HTTPClient()))
+
+	return exitCode, stdout.String(), stderr.String()
+}
+
+func testEnvironment(server *pasdktest.Server) map[string]string {
+	return map[string]string{
+		"PASDK_API_KEY":    pasdktest.APIKey,
+		"PASDK_API_SECRET": pasdktest.APISecret,
+		"PASDK_API_URL":    server.URL,
+	}
+}
+
+func Test_Run_BeginAndStatus(t *testing.T) {
+	server := pasdktest.NewServer()
+	defer server.Close()
+
+	environment := testEnvironment(server)
+
+	exitCode, stdout, stderr := runTestCommand(server, environment, "begin", "-output", "json",
+		"-order-id", "cli-order", "-amount", "£500", "-first-name", "Test", "-last-name", "Testington",
+		"-address1", "Test House", "-postcode", "TE1 1ST", "-auto-capture=false", "-dob", "1990-01-31")
+
+	if exitCode != exitOK {
+		t.Fatal(exitCode, stderr)
+	}
+
+	var begin pasdk.BeginResponse
+
+	if err := json.Unmarshal([]byte(stdout), &begin); err != nil {
+		t.Fatal(err)
+	}
+
+	application, _ := server.Application(begin.ApplicationToken)
+
+	if application.Amount != 50000 || application.AutoCapture || application.Params.Get("dob") != "1990-01-31" {
+		t.Error(application)
+	}
+
+	exitCode, stdout, _ = runTestCommand(server, environment, "status", "-token", begin.ApplicationToken)
+
+	if exitCode != exitOK || !strings.Contains(stdout, "pending") || !strings.Contains(stdout, "£500.00") {
+		t.Error(exitCode, stdout)
+	}
+}
+
+func Test_Run_ExitCodes(t *testing.T) {
+	server := pasdktest.NewServer()
+	defer server.Close()
+
+	environment := testEnvironment(server)
+
+	exitCode, _, _ := runTestCommand(server, environment, "nonsense")
+
+	if exitCode != exitUsage {
+		t.Error(exitCode)
+	}
+
+	exitCode, _, _ = runTestCommand(server, environment, "begin", "-dob", "31/01/1990")
+
+	if exitCode != exitUsage {
+		t.Error(exitCode)
+	}
+
+	exitCode, _, _ = runTestCommand(server, environment, "begin", "-order-id", "cli-order")
+
+	if exitCode != exitValidationFailed {
+		t.Error(exitCode)
+	}
+
+	exitCode, _, stderr := runTestCommand(server, environment, "status", "-token", "does-not-exist")
+
+	if exitCode != exitRequestRefused || !strings.Contains(stderr, "Application not found") {
+		t.Error(exitCode, stderr)
+	}
+
+	exitCode, _, _ = runTestCommand(server, map[string]string{}, "account")
+
+	if exitCode != exitValidationFailed {
+		t.Error(exitCode)
+	}
+}
+
+func Test_Run_ReadsConfigFile(t *testing.T) {
+	server := pasdktest.NewServer()
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	data, _ := json.Marshal(map[string]string{
+		"api_key":    pasdktest.APIKey,
+		"api_secret": "overridden",
+		"api_url":    server.URL,
+	})
+
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	environment := map[string]string{"PASDK_API_SECRET": pasdktest.APISecret}
+
+	exitCode, stdout, stderr := runTestCommand(server, environment, "account", "-config", configPath)
+
+	if exitCode != exitOK || !strings.Contains(stdout, "Test Merchant Ltd") || !strings.Contains(stdout, "12-Payment") {
+		t.Error(exitCode, stdout, stderr)
+	}
+}
+
+func Test_Run_Invoice(t *testing.T) {
+	server := pasdktest.NewServer()
+	defer server.Close()
+
+	environment := testEnvironment(server)
+
+	_, stdout, _ := runTestCommand(server, environment, "begin", "-output", "json", "-order-id", "invoice-order",
+		"-amount", "100", "-first-name", "Test", "-last-name", "Testington", "-address1", "Test House",
+		"-postcode", "TE1 1ST")
+
+	var begin pasdk.BeginResponse
+	json.Unmarshal([]byte(stdout), &begin)
+
+	if err := server.Approve(begin.ApplicationToken); err != nil {
+		t.Fatal(err)
+	}
+
+	invoicePath := filepath.Join(t.TempDir(), "invoice.txt")
+
+	if err := os.WriteFile(invoicePath, []byte("Test invoice"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	exitCode, stdout, stderr := runTestCommand(server, environment, "invoice", "-token", begin.ApplicationToken,
+		"-file", invoicePath)
+
+	if exitCode != exitOK || !strings.Contains(stdout, "success") {
+		t.Error(exitCode, stdout, stderr)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+)
+
+func printJSON(output io.Writer, response interface{}) error {
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(response)
+}
+
+// Prints a response as aligned rows of text, for people to read.
+func printTable(output io.Writer, response interface{}) error {
+	table := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+
+	row := func(columns ...string) {
+		for i, column := range columns {
+			if i > 0 {
+				io.WriteString(table, "\t")
+			}
+
+			io.WriteString(table, column)
+		}
+
+		io.WriteString(table, "\n")
+	}
+
+	switch response := response.(type) {
+	case *pasdk.AccountResponse:
+		row("Legal name", response.LegalName)
+		row("Display name", response.DisplayName)
+		row()
+		row("Plan ID", "Name", "Instalments", "Deposit", "APR", "Frequency", "Min amount", "Max amount")
+
+		for _, plan := range response.Plans {
+			row(strconv.Itoa(plan.ID), plan.Name, strconv.Itoa(plan.Instalments), yesNo(plan.DepositRequired),
+				plan.APR+"%", plan.Frequency, formatOptionalMoney(plan.MinAmount), formatOptionalMoney(plan.MaxAmount))
+		}
+	case *pasdk.PlanResponse:
+		row("Plan", response.PlanName)
+		row("Amount", response.Amount.String())
+		row("Interest", response.Interest.String())
+		row("Total repayable", response.TotalRepayable.String())
+		row()
+		row("Payment", "Date", "Amount")
+
+		for i, repayment := range response.PaymentSchedule {
+			row(strconv.Itoa(i+1), repayment.Date.Format("2006-01-02"), repayment.Amount.String())
+		}
+	case *pasdk.PreapprovalResponse:
+		row("Approved", yesNo(response.Approved))
+	case *pasdk.BeginResponse:
+		row("Token", response.ApplicationToken)
+		row("URL", response.ContinuationURL)
+	case *pasdk.StatusResponse:
+		row("Token", response.ApplicationToken)
+		row("Status", string(response.Status))
+		row("Amount", response.Amount.String())
+		row("Expires at", formatTime(response.ExpiresAt))
+		row("Payment Assist reference", response.PaymentAssistReference)
+		row("Requires invoice", yesNo(response.RequriesInvoice))
+		row("Has invoice", yesNo(response.HasInvoice))
+		row("Last accessed at", formatTime(response.LastAccessedAt))
+	case *pasdk.UpdateResponse:
+		row("Token", response.ApplicationToken)
+
+		if response.OrderID != nil {
+			row("Order ID", *response.OrderID)
+		}
+
+		if response.ExpiresIn != nil {
+			row("Expires in", strconv.Itoa(*response.ExpiresIn)+" seconds")
+		}
+
+		if response.Amount != nil {
+			row("Amount", response.Amount.String())
+		}
+	case *pasdk.CaptureResponse:
+		row("Token", response.ApplicationToken)
+		row("Status", string(response.Status))
+
+		if response.DepositCaptured != nil {
+			row("Deposit captured", yesNo(*response.DepositCaptured))
+		}
+
+		if response.DepositCaptureFailureReason != nil {
+			row("Deposit failure reason", *response.DepositCaptureFailureReason)
+		}
+	case *pasdk.InvoiceResponse:
+		row("Token", response.ApplicationToken)
+		row("Upload status", response.UploadStatus)
+	default:
+		return printJSON(output, response)
+	}
+
+	return table.Flush()
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+
+	return "no"
+}
+
+func formatOptionalMoney(money *pasdk.Money) string {
+	if money == nil {
+		return "-"
+	}
+
+	return money.String()
+}
+
+func formatTime(value time.Time) string {
+	if value.IsZero() {
+		return "-"
+	}
+
+	return value.Format(time.RFC3339)
+}
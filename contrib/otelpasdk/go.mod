@@ -0,0 +1,20 @@
+module github.com/paymentassist/paymentassist-go/contrib/otelpasdk
+
+go 1.19
+
+require (
+	github.com/paymentassist/paymentassist-go v0.0.0
+	go.opentelemetry.io/otel v1.16.0
+	go.opentelemetry.io/otel/sdk v1.16.0
+	go.opentelemetry.io/otel/trace v1.16.0
+)
+
+require (
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/otel/metric v1.16.0 // indirect
+	golang.org/x/sys v0.8.0 // indirect
+)
+
+// Use the SDK in this repository rather than a published version.
+replace github.com/paymentassist/paymentassist-go => ../..
@@ -0,0 +1,92 @@
+// Package otelpasdk adapts the SDK's tracing hooks to OpenTelemetry. It lives in its own
+// module so that the SDK itself doesn't depend on OpenTelemetry.
+//
+//	client := pasdk.NewClient(credentials, pasdk.WithTracer(otelpasdk.NewTracer()))
+//
+// Each attempt at sending a request to the API becomes a client span named after the
+// endpoint, such as "pasdk begin", with the HTTP method, status code, error type and
+// attempt number as attributes.
+package otelpasdk
+
+import (
+	"context"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The name of the instrumentation library, reported with every span.
+const instrumentationName = "github.com/paymentassist/paymentassist-go/contrib/otelpasdk"
+
+// The attributes set on every span.
+const (
+	EndpointKey   = attribute.Key("pasdk.endpoint")
+	MethodKey     = attribute.Key("http.method")
+	StatusCodeKey = attribute.Key("http.status_code")
+	ErrorTypeKey  = attribute.Key("pasdk.error_type")
+	AttemptKey    = attribute.Key("pasdk.attempt")
+)
+
+// Option configures a Tracer.
+type Option func(tracer *Tracer)
+
+// WithTracerProvider sets the TracerProvider spans are created with. The global
+// provider is used by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(tracer *Tracer) {
+		tracer.provider = provider
+	}
+}
+
+// Tracer implements pasdk.Tracer using OpenTelemetry.
+type Tracer struct {
+	provider trace.TracerProvider
+	tracer   trace.Tracer
+}
+
+// NewTracer creates a Tracer that can be passed to pasdk.WithTracer.
+func NewTracer(options ...Option) *Tracer {
+	tracer := &Tracer{provider: otel.GetTracerProvider()}
+
+	for _, option := range options {
+		option(tracer)
+	}
+
+	tracer.tracer = tracer.provider.Tracer(instrumentationName)
+
+	return tracer
+}
+
+// StartSpan starts an OpenTelemetry span for an attempt at sending a request.
+func (tracer *Tracer) StartSpan(ctx context.Context, info pasdk.SpanInfo) (context.Context, pasdk.Span) {
+	ctx, span := tracer.tracer.Start(ctx, "pasdk "+info.Endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			EndpointKey.String(info.Endpoint),
+			MethodKey.String(info.Method),
+			AttemptKey.Int(info.Attempt),
+		))
+
+	return ctx, otelSpan{span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (span otelSpan) End(result pasdk.SpanResult) {
+	if result.StatusCode != 0 {
+		span.span.SetAttributes(StatusCodeKey.Int(result.StatusCode))
+	}
+
+	if result.Err != nil {
+		span.span.SetAttributes(ErrorTypeKey.String(result.ErrorType))
+		span.span.RecordError(result.Err)
+		span.span.SetStatus(codes.Error, result.Err.Error())
+	}
+
+	span.span.End()
+}
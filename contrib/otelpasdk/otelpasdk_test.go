@@ -0,0 +1,61 @@
+package otelpasdk
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func Test_Tracer_RecordsSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	transport := pasdk.RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 404,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"status":"error","msg":"Application not found","data":[]}`)),
+			Request:    request,
+		}, nil
+	})
+
+	client := pasdk.NewClient(pasdk.PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		pasdk.WithTransport(transport),
+		pasdk.WithTracer(NewTracer(WithTracerProvider(provider))))
+
+	_, err := client.Status(pasdk.StatusRequest{ApplicationToken: "test"})
+
+	if err == nil {
+		t.Fatal()
+	}
+
+	spans := recorder.Ended()
+
+	if len(spans) != 1 {
+		t.Fatal(len(spans))
+	}
+
+	span := spans[0]
+
+	if span.Name() != "pasdk status" || span.Status().Code != codes.Error {
+		t.Error(span.Name(), span.Status())
+	}
+
+	attributes := map[string]string{}
+
+	for _, attribute := range span.Attributes() {
+		attributes[string(attribute.Key)] = attribute.Value.Emit()
+	}
+
+	if attributes["pasdk.endpoint"] != "status" || attributes["http.method"] != "GET" ||
+		attributes["http.status_code"] != "404" || attributes["pasdk.error_type"] != "RequestRefusedError" ||
+		attributes["pasdk.attempt"] != "1" {
+		t.Error(attributes)
+	}
+}
@@ -1,11 +1,13 @@
 package pasdk
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
 	"net/url"
@@ -14,181 +16,277 @@ import (
 	"time"
 )
 
-var requestClient *http.Client
-
-func getAPIRequestClient() *http.Client {
-	if requestClient == nil {
-		requestClient = &http.Client{
-			Timeout: 30 * time.Second,
-		}
-	}
-
-	return requestClient
-}
-
-func getRequestURL() (string, *PASDKError) {
-	if testsAreRunning && !shouldRunIntegrationTests() {
+func (client *Client) getRequestURL() (string, *PASDKError) {
+	if client.usesMockAPI() {
 		return "", nil
 	}
 
-	if !strings.Contains(userCredentials.APIURL, "https:") {
+	apiURL := client.credentials.APIURL
+
+	if !strings.Contains(apiURL, "https:") {
 		return "", buildValidationFailedError("the API URL must contain the string \"https:\"")
 	}
 
-	if userCredentials.APIURL[len(userCredentials.APIURL)-1:] != "/" {
-		return userCredentials.APIURL + "/", nil
+	if apiURL[len(apiURL)-1:] != "/" {
+		return apiURL + "/", nil
 	}
 
-	return userCredentials.APIURL, nil
+	return apiURL, nil
 }
 
 // Returns an error if there is an issue with the credentials.
-func checkCredentialsExist() *PASDKError {
-	if len(userCredentials.APIKey) == 0 {
+func (client *Client) checkCredentialsExist() *PASDKError {
+	if len(client.credentials.APIKey) == 0 {
 		return buildValidationFailedError("APIKey cannot be empty - call pasdk.Initialise to pass in your credentials")
 	}
-	if len(userCredentials.APISecret) == 0 {
+	if len(client.credentials.APISecret) == 0 {
 		return buildValidationFailedError("APISecret cannot be empty - call pasdk.Initialise to pass in your credentials")
 	}
 
-	if !testsAreRunning && len(userCredentials.APIURL) == 0 {
+	if !testsAreRunning && len(client.credentials.APIURL) == 0 {
 		return buildValidationFailedError("APIURL cannot be empty - call pasdk.Initialise to pass in the URL you want to send a request to")
 	}
 
 	return nil
 }
 
-func makeAPIPOSTRequest[T interface{}](formData []string, endpoint string) (*T, *PASDKError) {
-	paErr := checkCredentialsExist()
+func makeAPIPOSTRequest[T interface{}](ctx context.Context, client *Client, params []requestParam, endpoint string) (*T, *PASDKError) {
+	paErr := client.checkCredentialsExist()
 
 	if paErr != nil {
 		return nil, paErr
 	}
 
-	formValues := url.Values{}
+	client.logRequest(ctx, getEndpointName(endpoint), "POST", params)
 
-	for _, data := range formData {
-		parts := strings.Split(data, "=")
-		formValues.Set(parts[0], parts[1])
-	}
+	encodedForm := encodeParams(params)
 
-	if testsAreRunning && !shouldRunIntegrationTests() {
-		response, err := getMockAPIResponse[T](endpoint)
-		return response, err
-	}
+	newRequest := func() (*http.Request, error) {
+		request, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(encodedForm))
+
+		if err != nil {
+			return nil, err
+		}
 
-	request, err := http.NewRequest("POST", endpoint, strings.NewReader(formValues.Encode()))
+		request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-	if err != nil {
-		return nil, buildUnexpectedError("creating API request failed: " + err.Error())
+		return request, nil
 	}
 
-	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	request.Header.Add("X-Origin", "payment-assist-go-sdk")
+	return sendAPIRequest[T](ctx, client, getEndpointName(endpoint), newRequest)
+}
 
-	response, err := getAPIRequestClient().Do(request)
+// Returns an error if the status code indicated failure.
+func checkStatusCode(statusCode int, requestBody string) *PASDKError {
+	var paErr *PASDKError
 
-	if err != nil {
-		return nil, buildUnexpectedError("API request failed: " + err.Error())
+	if (statusCode >= 0 && statusCode < 200) ||
+		(statusCode >= 300 && statusCode < 400) ||
+		(statusCode >= 500 && statusCode < 600) {
+		paErr = buildUnexpectedError("API request failed returning status code " + toString(statusCode) +
+			": " + describeResponseBody(requestBody))
 	}
 
-	defer response.Body.Close()
+	if statusCode >= 400 && statusCode < 500 {
+		paErr = buildRequestRefusedError("API refused your request returning status code " + toString(statusCode) +
+			": " + describeResponseBody(requestBody))
+	}
 
-	body, err := io.ReadAll(response.Body)
+	if statusCode == 429 {
+		paErr = buildThrottledError("API is rate limiting your requests, returning status code 429: " +
+			describeResponseBody(requestBody))
+	}
 
-	if err != nil {
-		return nil, buildUnexpectedError("reading API response failed: " + err.Error())
+	if paErr == nil {
+		return nil
 	}
 
-	paErr = checkStatusCode(response.StatusCode, string(body))
+	paErr.StatusCode = statusCode
+	paErr.RawBody = requestBody
+	paErr.APIMessage = getAPIMessage(requestBody)
 
-	if paErr != nil {
-		return nil, paErr
+	return paErr
+}
+
+// Returns the "msg" field of a response from the API, or an empty string if the
+// response doesn't have one.
+func getAPIMessage(responseBody string) string {
+	var responseWrapper struct {
+		Message *string `json:"msg"`
 	}
 
-	output, paErr := decodeResponseJSON[T](body)
+	err := json.Unmarshal([]byte(responseBody), &responseWrapper)
 
-	if paErr != nil {
-		return nil, paErr
+	if err != nil || responseWrapper.Message == nil {
+		return ""
 	}
 
-	return output, nil
+	return *responseWrapper.Message
 }
 
-// Returns an error if the status code indicated failure.
-func checkStatusCode(statusCode int, requestBody string) *PASDKError {
-	if (statusCode >= 0 && statusCode < 200) ||
-		(statusCode >= 300 && statusCode < 400) ||
-		(statusCode >= 500 && statusCode < 600) {
-		return buildUnexpectedError("API request failed returning status code " + toString(statusCode) +
-			": " + requestBody)
-	}
+// Returns the API's error message from a response body if it has one, otherwise
+// the body itself, for use in error messages.
+func describeResponseBody(responseBody string) string {
+	message := getAPIMessage(responseBody)
 
-	if statusCode >= 400 && statusCode < 500 {
-		return buildRequestRefusedError("API refused your request returning status code " + toString(statusCode) +
-			": " + requestBody)
+	if len(message) > 0 {
+		return message
 	}
 
-	return nil
+	return responseBody
 }
 
-func makeAPIGETRequest[T interface{}](formData []string, endpoint string) (*T, *PASDKError) {
-	paErr := checkCredentialsExist()
+func makeAPIGETRequest[T interface{}](ctx context.Context, client *Client, params []requestParam, endpoint string) (*T, *PASDKError) {
+	paErr := client.checkCredentialsExist()
 
 	if paErr != nil {
 		return nil, paErr
 	}
 
-	endpoint += "?"
+	client.logRequest(ctx, getEndpointName(endpoint), "GET", params)
 
-	for _, data := range formData {
-		parts := strings.Split(data, "=")
+	endpoint += "?" + encodeParams(params)
 
-		endpoint += parts[0] + "=" + url.QueryEscape(parts[1]) + "&"
+	newRequest := func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	}
 
-	endpoint = endpoint[:len(endpoint)-1]
+	return sendAPIRequest[T](ctx, client, getEndpointName(endpoint), newRequest)
+}
+
+// Returns the name of the endpoint a request URL points to, such as "begin".
+func getEndpointName(endpoint string) string {
+	endpoint = strings.Split(endpoint, "?")[0]
+
+	return endpoint[strings.LastIndex(endpoint, "/")+1:]
+}
+
+// Sends a request to the API and decodes the response, retrying failed attempts
+// according to the client's retry policy. newRequest is called once per attempt.
+func sendAPIRequest[T interface{}](ctx context.Context, client *Client, endpointName string,
+	newRequest func() (*http.Request, error)) (*T, *PASDKError) {
+	if client.usesMockAPI() {
+		output, paErr := getMockAPIResponseContext[T](ctx, endpointName)
 
-	if testsAreRunning && !shouldRunIntegrationTests() {
-		response, err := getMockAPIResponse[T](endpoint)
-		return response, err
+		if paErr != nil {
+			paErr.Endpoint = endpointName
+		}
+
+		return output, paErr
 	}
 
-	request, err := http.NewRequest("GET", endpoint, nil)
+	for attempt := 1; ; attempt++ {
+		release, waitErr := client.waitForRateLimit(ctx, endpointName)
+
+		if waitErr != nil {
+			waitErr.Endpoint = endpointName
+			return nil, waitErr
+		}
+
+		output, paErr, retryable := attemptAPIRequest[T](ctx, client, endpointName, attempt, newRequest)
+		release()
+
+		if paErr != nil && paErr.IsThrottledError {
+			client.pauseRateLimit(endpointName, paErr.RetryAfter)
+		}
+
+		if paErr == nil || !retryable || attempt >= client.retryPolicy.maxAttempts() {
+			return output, paErr
+		}
+
+		backoff, retry := client.retryPolicy.nextBackoff(attempt, paErr)
+
+		if !retry {
+			return output, paErr
+		}
+
+		waitErr = sleepContext(ctx, backoff)
+
+		if waitErr != nil {
+			waitErr.Endpoint = endpointName
+			return nil, waitErr
+		}
+	}
+}
+
+// Makes a single attempt at sending a request to the API. The returned bool reports
+// whether the attempt failed in a way that is safe to retry.
+func attemptAPIRequest[T interface{}](ctx context.Context, client *Client, endpointName string, attempt int,
+	newRequest func() (*http.Request, error)) (output *T, paErr *PASDKError, retryable bool) {
+	statusCode := 0
+	endSpan := func(int, *PASDKError) {}
+	var started time.Time
+
+	defer func() {
+		if paErr != nil {
+			paErr.Endpoint = endpointName
+		}
+
+		endSpan(statusCode, paErr)
+
+		if !started.IsZero() {
+			client.logResponse(ctx, endpointName, attempt, statusCode, time.Since(started), paErr)
+		}
+	}()
+
+	request, err := newRequest()
 
 	if err != nil {
-		return nil, buildUnexpectedError("creating API request failed: " + err.Error())
+		return nil, buildUnexpectedError("creating API request failed: " + err.Error()).withCause(err), false
 	}
 
 	request.Header.Add("X-Origin", "payment-assist-go-sdk")
 
-	response, err := getAPIRequestClient().Do(request)
+	endSpan = client.startSpan(&request, endpointName, attempt)
+	started = time.Now()
+
+	response, err := client.httpClient.Do(request)
 
 	if err != nil {
-		return nil, buildUnexpectedError("API request failed: " + err.Error())
+		if ctx.Err() != nil {
+			return nil, buildCancelledError("API request was cancelled: " + ctx.Err().Error()).withCause(ctx.Err()), false
+		}
+
+		return nil, buildUnexpectedError("API request failed: " + err.Error()).withCause(err),
+			isRetryableFailure(endpointName, 0, err)
 	}
 
 	defer response.Body.Close()
 
+	statusCode = response.StatusCode
+
 	body, err := io.ReadAll(response.Body)
 
 	if err != nil {
-		return nil, buildUnexpectedError("reading API response failed: " + err.Error())
+		if ctx.Err() != nil {
+			return nil, buildCancelledError("API request was cancelled while reading the response: " +
+				ctx.Err().Error()).withCause(ctx.Err()), false
+		}
+
+		paErr = buildUnexpectedError("reading API response failed: " + err.Error()).withCause(err)
+		paErr.StatusCode = response.StatusCode
+
+		return nil, paErr, isRetryableFailure(endpointName, 0, err)
 	}
 
 	paErr = checkStatusCode(response.StatusCode, string(body))
 
+	if paErr != nil && paErr.IsThrottledError {
+		paErr.RetryAfter = parseRetryAfter(response.Header.Get("Retry-After"), time.Now())
+	}
+
 	if paErr != nil {
-		return nil, paErr
+		return nil, paErr, isRetryableFailure(endpointName, response.StatusCode, nil)
 	}
 
-	output, paErr := decodeResponseJSON[T](body)
+	output, paErr = decodeResponseJSON[T](body)
 
 	if paErr != nil {
-		return nil, paErr
+		paErr.StatusCode = response.StatusCode
+		return nil, paErr, false
 	}
 
-	return output, nil
+	return output, nil, false
 }
 
 func catchGenericPanic[T interface{}](response **T, err **PASDKError) {
@@ -219,6 +317,21 @@ func buildRequestRefusedError(message string) *PASDKError {
 	}
 }
 
+func buildThrottledError(message string) *PASDKError {
+	return &PASDKError{
+		IsRequestRefusedError: true,
+		IsThrottledError:      true,
+		errorMessage:          message,
+	}
+}
+
+func buildCancelledError(message string) *PASDKError {
+	return &PASDKError{
+		IsCancelledError: true,
+		errorMessage:     message,
+	}
+}
+
 func buildUnexpectedError(message string) *PASDKError {
 	return &PASDKError{
 		errorMessage:      message,
@@ -226,6 +339,12 @@ func buildUnexpectedError(message string) *PASDKError {
 	}
 }
 
+// Sets the underlying cause of this error and returns it.
+func (err *PASDKError) withCause(cause error) *PASDKError {
+	err.Cause = cause
+	return err
+}
+
 // Returns an error if the request failed, or if something else went wrong.
 func decodeResponseJSON[T interface{}](jsonData []byte) (*T, *PASDKError) {
 	if len(jsonData) == 0 {
@@ -241,15 +360,25 @@ func decodeResponseJSON[T interface{}](jsonData []byte) (*T, *PASDKError) {
 	err := json.Unmarshal(jsonData, &statusResponseWrapper)
 
 	if err != nil {
-		return nil, buildUnexpectedError("failed to parse API response: " + err.Error())
+		paErr := buildUnexpectedError("failed to parse API response: " + err.Error()).withCause(err)
+		paErr.RawBody = string(jsonData)
+
+		return nil, paErr
 	}
 
 	if statusResponseWrapper.Status == "error" {
-		return nil, buildRequestRefusedError("the API refused your request: " + string(jsonData))
+		paErr := buildRequestRefusedError("the API refused your request: " + describeResponseBody(string(jsonData)))
+		paErr.RawBody = string(jsonData)
+		paErr.APIMessage = getAPIMessage(string(jsonData))
+
+		return nil, paErr
 	}
 
 	if statusResponseWrapper.Status != "ok" {
-		return nil, buildUnexpectedError("the API returned an unexpected response: " + string(jsonData))
+		paErr := buildUnexpectedError("the API returned an unexpected response: " + string(jsonData))
+		paErr.RawBody = string(jsonData)
+
+		return nil, paErr
 	}
 
 	// Now we can be sure the response was successful.
@@ -262,45 +391,65 @@ func decodeResponseJSON[T interface{}](jsonData []byte) (*T, *PASDKError) {
 	err = json.Unmarshal(jsonData, &responseWrapper)
 
 	if err != nil {
-		return nil, buildUnexpectedError("parsing JSON failed: " + err.Error())
+		paErr := buildUnexpectedError("parsing JSON failed: " + err.Error()).withCause(err)
+		paErr.RawBody = string(jsonData)
+
+		return nil, paErr
 	}
 
 	return &responseWrapper.Data, nil
 }
 
-// The keys of requestParams should already be in alphabetical order.
-func generateSignature(requestParams []string, secret string) string {
-	requestParams = capitaliseParamKeys(requestParams)
-	requestString := strings.Join(requestParams, "&")
+// requestParam is a single parameter of a request to the API. Parameters are kept as
+// separate keys and values, rather than "key=value" strings, so that values containing
+// characters such as '=' and '&' are sent and signed exactly as they are.
+type requestParam struct {
+	key   string
+	value string
+}
 
-	if len(requestString) > 0 {
-		requestString += "&"
+// Returns the parameters URL-encoded in the order given, for use as a query string
+// or form body.
+func encodeParams(params []requestParam) string {
+	encoded := make([]string, 0, len(params))
+
+	for _, param := range params {
+		encoded = append(encoded, url.QueryEscape(param.key)+"="+url.QueryEscape(param.value))
 	}
 
+	return strings.Join(encoded, "&")
+}
+
+// The keys of requestParams should already be in alphabetical order.
+func generateSignature(requestParams []requestParam, secret string) string {
 	hasher := hmac.New(sha256.New, []byte(secret))
-	hasher.Write([]byte(requestString))
+	writeSignedParams(hasher, requestParams)
 	return hex.EncodeToString(hasher.Sum(nil))
 }
 
-func capitaliseParamKeys(params []string) []string {
-	output := make([]string, 0, len(params))
+// Writes the parameters to hasher in the form they are signed in, which is
+// "KEY=value&" for each parameter.
+func writeSignedParams(hasher hash.Hash, params []requestParam) {
+	for _, param := range capitaliseParamKeys(params) {
+		hasher.Write([]byte(param.key + "=" + param.value + "&"))
+	}
+}
+
+func capitaliseParamKeys(params []requestParam) []requestParam {
+	output := make([]requestParam, 0, len(params))
 
 	for _, param := range params {
-		parts := strings.Split(param, "=")
-
-		output = append(output, strings.ToUpper(parts[0])+"="+parts[1])
+		output = append(output, requestParam{strings.ToUpper(param.key), param.value})
 	}
 
 	return output
 }
 
-func removeEmptyParams(params []string) []string {
-	output := make([]string, 0, len(params))
+func removeEmptyParams(params []requestParam) []requestParam {
+	output := make([]requestParam, 0, len(params))
 
 	for _, param := range params {
-		value := strings.Split(param, "=")[1]
-
-		if len(value) > 0 {
+		if len(param.value) > 0 {
 			output = append(output, param)
 		}
 	}
@@ -330,6 +479,14 @@ func toString(input interface{}) string {
 		}
 
 		return strconv.Itoa(*input)
+	case Money:
+		return strconv.Itoa(int(input))
+	case *Money:
+		if input == nil {
+			return ""
+		}
+
+		return strconv.Itoa(int(*input))
 	case bool:
 		if input {
 			return "true"
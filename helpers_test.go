@@ -1,6 +1,12 @@
 package pasdk
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
@@ -55,7 +61,7 @@ func Test_checkCredentialsExist(t *testing.T) {
 		return
 	}
 
-	currentCredentials := userCredentials
+	currentCredentials := defaultClient.credentials
 
 	defer func() {
 		Initialise(currentCredentials)
@@ -65,7 +71,7 @@ func Test_checkCredentialsExist(t *testing.T) {
 	testsAreRunning = false
 	Initialise(PAAuth{})
 
-	if checkCredentialsExist().Error() != "APIKey cannot be empty - call pasdk.Initialise to pass in your credentials" {
+	if defaultClient.checkCredentialsExist().Error() != "APIKey cannot be empty - call pasdk.Initialise to pass in your credentials" {
 		t.Error()
 	}
 
@@ -73,7 +79,7 @@ func Test_checkCredentialsExist(t *testing.T) {
 		APIKey: "test",
 	})
 
-	if checkCredentialsExist().Error() != "APISecret cannot be empty - call pasdk.Initialise to pass in your credentials" {
+	if defaultClient.checkCredentialsExist().Error() != "APISecret cannot be empty - call pasdk.Initialise to pass in your credentials" {
 		t.Error()
 	}
 
@@ -82,7 +88,7 @@ func Test_checkCredentialsExist(t *testing.T) {
 		APISecret: "test",
 	})
 
-	if checkCredentialsExist().Error() != "APIURL cannot be empty - call pasdk.Initialise to pass in the URL you want to send a request to" {
+	if defaultClient.checkCredentialsExist().Error() != "APIURL cannot be empty - call pasdk.Initialise to pass in the URL you want to send a request to" {
 		t.Error()
 	}
 
@@ -92,7 +98,7 @@ func Test_checkCredentialsExist(t *testing.T) {
 		APIURL:    "https://test.com",
 	})
 
-	if checkCredentialsExist() != nil {
+	if defaultClient.checkCredentialsExist() != nil {
 		t.Error()
 	}
 }
@@ -102,7 +108,7 @@ func Test_getRequestURL(t *testing.T) {
 		return
 	}
 
-	currentCredentials := userCredentials
+	currentCredentials := defaultClient.credentials
 
 	defer func() {
 		os.Unsetenv("GO_PASDK_INTEGRATION_TESTS")
@@ -116,7 +122,7 @@ func Test_getRequestURL(t *testing.T) {
 		APIURL: "https://testurl",
 	})
 
-	url, err := getRequestURL()
+	url, err := defaultClient.getRequestURL()
 
 	if url != "https://testurl/" {
 		t.Error()
@@ -129,7 +135,7 @@ func Test_getRequestURL(t *testing.T) {
 		APIURL: "https://testurl/",
 	})
 
-	url, err = getRequestURL()
+	url, err = defaultClient.getRequestURL()
 
 	if url != "https://testurl/" {
 		t.Error()
@@ -142,7 +148,7 @@ func Test_getRequestURL(t *testing.T) {
 		APIURL: "www.testurl",
 	})
 
-	url, err = getRequestURL()
+	url, err = defaultClient.getRequestURL()
 
 	if url != "" {
 		t.Error()
@@ -320,9 +326,9 @@ func Test_toString(t *testing.T) {
 }
 
 func Test_generateSignature(t *testing.T) {
-	params := []string{
-		"test=test",
-		"test2=test2",
+	params := []requestParam{
+		{"test", "test"},
+		{"test2", "test2"},
 	}
 
 	hash := "7eba7f616af343d16ff09e242362345e6cfb09d24b78a73c81d267f049fc47c2"
@@ -334,9 +340,9 @@ func Test_generateSignature(t *testing.T) {
 }
 
 func Test_generateSignature_2(t *testing.T) {
-	params := []string{
-		"test1=test",
-		"test2=test2",
+	params := []requestParam{
+		{"test1", "test"},
+		{"test2", "test2"},
 	}
 
 	hash := "8226de39365226038be9598213e480d22f4dfe7147f50d977087a8d4eb124f52"
@@ -348,18 +354,153 @@ func Test_generateSignature_2(t *testing.T) {
 }
 
 func Test_capitaliseParamKeys(t *testing.T) {
-	keys := []string{
-		"test1=test test",
-		"test2=test",
+	keys := []requestParam{
+		{"test1", "test test"},
+		{"test2", "test"},
 	}
 
 	keys = capitaliseParamKeys(keys)
 
-	if keys[0] != "TEST1=test test" {
+	if keys[0] != (requestParam{"TEST1", "test test"}) {
 		t.Error()
 	}
 
-	if keys[1] != "TEST2=test" {
+	if keys[1] != (requestParam{"TEST2", "test"}) {
+		t.Error()
+	}
+}
+
+func Test_removeEmptyParams(t *testing.T) {
+	params := removeEmptyParams([]requestParam{
+		{"empty", ""},
+		{"padding", "="},
+		{"test", "test"},
+	})
+
+	if len(params) != 2 || params[0].value != "=" || params[1].key != "test" {
+		t.Error(params)
+	}
+}
+
+func Test_generateSignature_ValuesContainingSeparators(t *testing.T) {
+	params := []requestParam{
+		{"filedata", "dGVzdA=="},
+		{"success_url", "https://example.com/done?order=1&status=ok"},
+	}
+
+	hasher := hmac.New(sha256.New, []byte("secret"))
+	hasher.Write([]byte("FILEDATA=dGVzdA==&SUCCESS_URL=https://example.com/done?order=1&status=ok&"))
+
+	if generateSignature(params, "secret") != hex.EncodeToString(hasher.Sum(nil)) {
+		t.Error()
+	}
+}
+
+func Test_encodeParams_RoundTripsValues(t *testing.T) {
+	params := []requestParam{
+		{"filedata", "dGVzdA=="},
+		{"success_url", "https://example.com/done?order=1&status=ok"},
+		{"description", "50% off + free delivery"},
+	}
+
+	decoded, err := url.ParseQuery(encodeParams(params))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, param := range params {
+		if decoded.Get(param.key) != param.value {
+			t.Error(param.key, decoded.Get(param.key))
+		}
+	}
+}
+
+func Test_makeAPIPOSTRequest_SendsValuesExactly(t *testing.T) {
+	var received url.Values
+
+	if shouldRunIntegrationTests() {
+		return
+	}
+
+	_, client := newTestServerClient(t, PAAuth{APIKey: "key", APISecret: "secret"},
+		func(writer http.ResponseWriter, request *http.Request) {
+			request.ParseForm()
+			received = request.PostForm
+			writer.Write([]byte(`{"status":"ok","msg":null,"data":{"token":"test","upload_status":"success"}}`))
+		})
+
+	fileData := []byte("test")
+
+	_, err := client.Invoice(InvoiceRequest{ApplicationToken: "test", FileType: "txt", FileData: fileData})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if received.Get("filedata") != "dGVzdA==" {
+		t.Error(received.Get("filedata"))
+	}
+
+	expectedSignature := generateSignature([]requestParam{
+		{"filedata", "dGVzdA=="},
+		{"filetype", "txt"},
+		{"token", "test"},
+	}, "secret")
+
+	if received.Get("signature") != expectedSignature {
+		t.Error(received.Get("signature"))
+	}
+}
+
+func Test_checkStatusCode_ParsesAPIMessage(t *testing.T) {
+	body := `{"status":"error","msg":"Application is not awaiting capture","data":[]}`
+
+	err := checkStatusCode(400, body)
+
+	if err.StatusCode != 400 {
+		t.Error()
+	}
+	if err.APIMessage != "Application is not awaiting capture" {
+		t.Error(err.APIMessage)
+	}
+	if err.RawBody != body {
+		t.Error()
+	}
+	if err.Error() != "API refused your request returning status code 400: Application is not awaiting capture" {
+		t.Error(err.Error())
+	}
+
+	err = checkStatusCode(502, "<html>Bad gateway</html>")
+
+	if err.APIMessage != "" {
+		t.Error()
+	}
+	if err.Error() != "API request failed returning status code 502: <html>Bad gateway</html>" {
+		t.Error(err.Error())
+	}
+}
+
+func Test_sendAPIRequest_PopulatesErrorDetails(t *testing.T) {
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithTransport(newStaticTransport(422, `{"status":"error","msg":"Invalid token","data":[]}`)))
+
+	_, err := client.Status(StatusRequest{ApplicationToken: "test"})
+
+	if err == nil {
+		t.Error()
+		return
+	}
+	if err.Endpoint != "status" {
+		t.Error(err.Endpoint)
+	}
+	if err.StatusCode != 422 {
+		t.Error(err.StatusCode)
+	}
+	if err.APIMessage != "Invalid token" {
+		t.Error(err.APIMessage)
+	}
+	if !errors.Is(err, ErrRequestRefused) {
 		t.Error()
 	}
 }
@@ -1,9 +1,11 @@
 package pasdk
 
-var userCredentials PAAuth
-
 // Initialises the SDK with your API credentials as well as the API URL
-// you want to make requests to.
-func Initialise(credentials PAAuth) {
-	userCredentials = credentials
+// you want to make requests to. The Fetch method on each request type
+// uses these credentials. If you need to use more than one set of credentials
+// at the same time, create a Client for each of them with NewClient instead.
+// Any options are applied to the client used by Fetch in the same way as they
+// are by NewClient.
+func Initialise(credentials PAAuth, options ...ClientOption) {
+	defaultClient = NewClient(credentials, options...)
 }
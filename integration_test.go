@@ -1,6 +1,7 @@
 package pasdk
 
 import (
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -30,7 +31,7 @@ func Test_IntegrationTests(t *testing.T) {
 
 func testUpdate(t *testing.T, token string) {
 	// Test only updating some fields.
-	amount := 80000
+	amount := Money(80000)
 
 	request := UpdateRequest{
 		ApplicationToken: token,
@@ -162,7 +163,7 @@ func testPreapproval(t *testing.T) {
 		CustomerFirstName: "Test",
 		CustomerLastName:  "Testington",
 		CustomerAddress1:  "Test House",
-		CustomerPostcode:  "TEST TES",
+		CustomerPostcode:  "TE1 1ST",
 	}
 
 	response, err := request.Fetch()
@@ -185,7 +186,16 @@ func testCapture(t *testing.T, token string) {
 
 	// This is the closest we can get to testing it because only a completed application
 	// can be captured.
-	if !strings.Contains(err.Error(), `{"status":"error","msg":"Application is not awaiting capture","data":[]}`) {
+	if !strings.Contains(err.Error(), "Application is not awaiting capture") {
+		t.Error(err.Error())
+	}
+	if err.APIMessage != "Application is not awaiting capture" {
+		t.Error(err.APIMessage)
+	}
+	if err.Endpoint != "capture" {
+		t.Error(err.Endpoint)
+	}
+	if !errors.Is(err, ErrRequestRefused) {
 		t.Error()
 	}
 }
@@ -218,7 +228,7 @@ func testBegin(t *testing.T) *BeginResponse {
 		CustomerFirstName: "Test",
 		CustomerLastName:  "Testington",
 		CustomerAddress1:  "Test House",
-		CustomerPostcode:  "TEST TES",
+		CustomerPostcode:  "TE1 1ST",
 		EnableAutoCapture: &falseValue,
 		DOB:               &date,
 	}
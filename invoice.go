@@ -1,14 +1,15 @@
 package pasdk
 
 import (
+	"context"
 	"encoding/base64"
 )
 
 // InvoiceRequest allows you to upload an invoice for a completed application.
 type InvoiceRequest struct {
-	ApplicationToken string // The token you received when calling the "begin" endpoint.
-	FileType         string // The file type. Some supported options are "pdf", "html", "txt", "doc" and "xls".
-	FileData         []byte // The file as a slice of bytes.
+	ApplicationToken string          // The token you received when calling the "begin" endpoint.
+	FileType         InvoiceFileType // The file type, such as FileTypePDF. If this is empty, the type is detected from FileData.
+	FileData         []byte          // The file as a slice of bytes. To upload a large file without loading it into memory, use InvoiceStreamRequest instead.
 }
 
 // InvoiceResponse contains the data returned by a call to the "invoice" endpoint. Unlike some
@@ -18,37 +19,69 @@ type InvoiceResponse struct {
 	UploadStatus     string `json:"upload_status"` // The status of the upload ("success" or "failed").
 }
 
-// Fetch executes the request.
-func (request InvoiceRequest) Fetch() (response *InvoiceResponse, err *PASDKError) {
+// Fetch executes the request using the credentials passed to Initialise.
+func (request InvoiceRequest) Fetch() (*InvoiceResponse, *PASDKError) {
+	return defaultClient.InvoiceContext(context.Background(), request)
+}
+
+// FetchContext executes the request using the credentials passed to Initialise. The
+// request is abandoned as soon as ctx is cancelled or its deadline passes.
+func (request InvoiceRequest) FetchContext(ctx context.Context) (*InvoiceResponse, *PASDKError) {
+	return defaultClient.InvoiceContext(ctx, request)
+}
+
+// Validate checks the request without sending it, and returns a validation error listing
+// every problem found in FieldErrors, or nil if the request is valid. FileData is checked
+// against FileType, which is detected from FileData if it is empty.
+func (request InvoiceRequest) Validate() *PASDKError {
+	return validateInvoiceRequest(applyInvoiceDefaults(request))
+}
+
+// Invoice executes the given request using this client's credentials.
+func (client *Client) Invoice(request InvoiceRequest) (*InvoiceResponse, *PASDKError) {
+	return client.InvoiceContext(context.Background(), request)
+}
+
+// InvoiceContext is like Invoice, but the request is abandoned as soon as ctx is
+// cancelled or its deadline passes.
+func (client *Client) InvoiceContext(ctx context.Context, request InvoiceRequest) (response *InvoiceResponse, err *PASDKError) {
+	defer client.logFailure(ctx, "invoice", &err)
 	defer catchGenericPanic(&response, &err)
 
-	err = validateInvoiceRequest(request)
+	request = applyInvoiceDefaults(request)
+	validation := invoiceValidation(request)
+
+	if int64(len(request.FileData)) > client.maxInvoiceSize {
+		validation.check(invoiceTooLarge(client.maxInvoiceSize))
+	}
+
+	err = validation.err()
 
 	if err != nil {
 		return nil, err.Wrap("request is invalid: ")
 	}
 
 	// Alphabetically sorted.
-	requestParams := []string{
-		"filedata=" + base64.StdEncoding.EncodeToString(request.FileData),
-		"filetype=" + request.FileType,
-		"token=" + request.ApplicationToken,
+	requestParams := []requestParam{
+		{"filedata", base64.StdEncoding.EncodeToString(request.FileData)},
+		{"filetype", string(request.FileType)},
+		{"token", request.ApplicationToken},
 	}
 
 	requestParams = removeEmptyParams(requestParams)
 
-	signature := generateSignature(requestParams, userCredentials.APISecret)
+	signature := generateSignature(requestParams, client.credentials.APISecret)
 
-	requestParams = append(requestParams, "api_key="+userCredentials.APIKey)
-	requestParams = append(requestParams, "signature="+signature)
+	requestParams = append(requestParams, requestParam{"api_key", client.credentials.APIKey})
+	requestParams = append(requestParams, requestParam{"signature", signature})
 
-	requestURL, err := getRequestURL()
+	requestURL, err := client.getRequestURL()
 
 	if err != nil {
 		return nil, err.Wrap("failed determining request URL: ")
 	}
 
-	response, err = makeAPIPOSTRequest[InvoiceResponse](requestParams, requestURL+"invoice")
+	response, err = makeAPIPOSTRequest[InvoiceResponse](ctx, client, requestParams, requestURL+"invoice")
 
 	if err != nil {
 		return nil, err.Wrap("API request failed: ")
@@ -57,18 +90,31 @@ func (request InvoiceRequest) Fetch() (response *InvoiceResponse, err *PASDKErro
 	return response, nil
 }
 
-func validateInvoiceRequest(request InvoiceRequest) (err *PASDKError) {
-	if len(request.ApplicationToken) == 0 {
-		return buildValidationFailedError("ApplicationToken cannot be empty")
-	}
+func validateInvoiceRequest(request InvoiceRequest) *PASDKError {
+	validation := invoiceValidation(request)
+
+	return validation.err()
+}
+
+// Checks the fields of the request, apart from the size of FileData, which depends on the client.
+func invoiceValidation(request InvoiceRequest) validation {
+	validation := validation{}
 
-	if len(request.FileType) == 0 {
-		return buildValidationFailedError("FileType cannot be empty")
+	validation.required("ApplicationToken", request.ApplicationToken)
+
+	if len(request.FileType) == 0 && len(request.FileData) > 0 {
+		validation.fail("FileType", FieldErrorRequired, "FileType cannot be empty as the type of FileData couldn't be detected")
+	} else {
+		validation.required("FileType", string(request.FileType))
 	}
 
-	if len(request.FileData) == 0 {
-		return buildValidationFailedError("FileData cannot be empty")
+	validation.required("FileData", string(request.FileData))
+
+	if len(request.FileType) > 0 && len(request.FileData) > 0 {
+		validation.check(checkInvoiceFileType(request.FileType, request.FileData))
+	} else if len(request.FileType) > 0 {
+		validation.check(checkInvoiceFileTypeSupported(request.FileType))
 	}
 
-	return nil
+	return validation
 }
@@ -0,0 +1,130 @@
+package pasdk
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// InvoiceFileType is the type of an invoice file, given as its usual file extension.
+type InvoiceFileType string
+
+// The invoice file types that can be uploaded.
+const (
+	FileTypePDF  InvoiceFileType = "pdf"
+	FileTypeHTML InvoiceFileType = "html"
+	FileTypeTXT  InvoiceFileType = "txt"
+	FileTypeDOC  InvoiceFileType = "doc"
+	FileTypeXLS  InvoiceFileType = "xls"
+)
+
+// SupportedInvoiceFileTypes lists every file type that can be uploaded as an invoice.
+var SupportedInvoiceFileTypes = []InvoiceFileType{
+	FileTypePDF, FileTypeHTML, FileTypeTXT, FileTypeDOC, FileTypeXLS,
+}
+
+// The number of bytes from the start of a file that are used to detect its type.
+const fileTypeSniffLength = 8192
+
+// IsSupported returns true if invoices of this type can be uploaded.
+func (fileType InvoiceFileType) IsSupported() bool {
+	for _, supported := range SupportedInvoiceFileTypes {
+		if fileType == supported {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The kinds of file that can be told apart from their first few bytes. Some kinds could
+// be one of several file types, for example Word and Excel files both use the OLE format.
+type sniffedFile struct {
+	description   string            // What the file looks like, for use in error messages.
+	detectedType  InvoiceFileType   // The file type to use if none was given, or empty if it can't be told.
+	possibleTypes []InvoiceFileType // The file types the contents are valid for.
+}
+
+// Works out what kind of file data is from its first fileTypeSniffLength bytes.
+func sniffInvoiceFile(data []byte) sniffedFile {
+	if len(data) > fileTypeSniffLength {
+		data = data[:fileTypeSniffLength]
+	}
+
+	if bytes.HasPrefix(data, []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")) {
+		return sniffedFile{"a Word or Excel 97-2003 document", "", []InvoiceFileType{FileTypeDOC, FileTypeXLS}}
+	}
+
+	contentType := http.DetectContentType(data)
+
+	switch {
+	case contentType == "application/pdf":
+		return sniffedFile{"a PDF document", FileTypePDF, []InvoiceFileType{FileTypePDF}}
+	case strings.HasPrefix(contentType, "text/html"):
+		return sniffedFile{"an HTML document", FileTypeHTML, []InvoiceFileType{FileTypeHTML, FileTypeTXT}}
+	case strings.HasPrefix(contentType, "text/plain"):
+		return sniffedFile{"a text file", FileTypeTXT, []InvoiceFileType{FileTypeTXT, FileTypeHTML}}
+	case contentType == "application/zip":
+		// Word and Excel files from 2007 onwards (docx and xlsx) are zip archives, and
+		// can't be uploaded.
+		return sniffedFile{"a zip archive, such as a docx or xlsx file, which is not supported", "", nil}
+	case strings.HasPrefix(contentType, "image/"):
+		return sniffedFile{"an image (" + contentType + ")", "", nil}
+	}
+
+	return sniffedFile{"an unrecognised binary file", "", nil}
+}
+
+// DetectInvoiceFileType works out the type of an invoice from its contents, which only need
+// to include the first few kilobytes of the file. It returns false if the type can't be
+// determined, either because the file isn't a supported type or because it could be more
+// than one, such as Word and Excel 97-2003 files, which share a format.
+func DetectInvoiceFileType(data []byte) (InvoiceFileType, bool) {
+	detectedType := sniffInvoiceFile(data).detectedType
+
+	return detectedType, len(detectedType) > 0
+}
+
+// Returns the problem with FileType if it isn't supported, or if data isn't a valid file
+// of that type. Only the first few kilobytes of the file are needed.
+func checkInvoiceFileType(fileType InvoiceFileType, data []byte) *FieldError {
+	if problem := checkInvoiceFileTypeSupported(fileType); problem != nil {
+		return problem
+	}
+
+	sniffed := sniffInvoiceFile(data)
+
+	for _, possibleType := range sniffed.possibleTypes {
+		if fileType == possibleType {
+			return nil
+		}
+	}
+
+	return &FieldError{"FileType", FieldErrorFileTypeMismatch,
+		"FileType is \"" + string(fileType) + "\" but the file is " + sniffed.description}
+}
+
+// Returns the problem with FileType if it isn't a type that can be uploaded.
+func checkInvoiceFileTypeSupported(fileType InvoiceFileType) *FieldError {
+	if !fileType.IsSupported() {
+		return &FieldError{"FileType", FieldErrorUnsupportedFileType, "FileType \"" + string(fileType) + "\" is not supported"}
+	}
+
+	return nil
+}
+
+// Fills in FileType from the file's contents if it is empty, and converts it to lower case.
+func applyInvoiceDefaults(request InvoiceRequest) InvoiceRequest {
+	request.FileType = defaultInvoiceFileType(request.FileType, request.FileData)
+
+	return request
+}
+
+// Returns fileType in lower case, or the type detected from data if fileType is empty.
+func defaultInvoiceFileType(fileType InvoiceFileType, data []byte) InvoiceFileType {
+	if len(fileType) == 0 && len(data) > 0 {
+		fileType, _ = DetectInvoiceFileType(data)
+	}
+
+	return InvoiceFileType(strings.ToLower(string(fileType)))
+}
@@ -0,0 +1,133 @@
+package pasdk
+
+import (
+	"strings"
+	"testing"
+)
+
+var (
+	testPDF  = []byte("%PDF-1.7\n%\xE2\xE3\xCF\xD3\n1 0 obj\n")
+	testPNG  = []byte("\x89PNG\r\n\x1A\n\x00\x00\x00\rIHDR")
+	testOLE  = []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1\x00\x00\x00\x00")
+	testDOCX = []byte("PK\x03\x04\x14\x00\x06\x00\x08\x00\x00\x00!\x00[Content_Types].xml PK\x03\x04 word/document.xml")
+	testHTML = []byte("  <!DOCTYPE html><html><body>Invoice</body></html>")
+)
+
+func Test_DetectInvoiceFileType(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		expected InvoiceFileType
+	}{
+		{testPDF, FileTypePDF},
+		{testHTML, FileTypeHTML},
+		{[]byte("Invoice for £100"), FileTypeTXT},
+	}
+
+	for _, test := range tests {
+		fileType, detected := DetectInvoiceFileType(test.data)
+
+		if !detected || fileType != test.expected {
+			t.Error(test.expected, fileType)
+		}
+	}
+
+	for _, data := range [][]byte{testPNG, testOLE, testDOCX, []byte("\x00\x01\x02\x03")} {
+		if fileType, detected := DetectInvoiceFileType(data); detected {
+			t.Error(fileType)
+		}
+	}
+}
+
+func Test_checkInvoiceFileType(t *testing.T) {
+	if checkInvoiceFileType(FileTypePDF, testPDF) != nil {
+		t.Error()
+	}
+	if checkInvoiceFileType(FileTypeDOC, testOLE) != nil || checkInvoiceFileType(FileTypeXLS, testOLE) != nil {
+		t.Error()
+	}
+	if checkInvoiceFileType(FileTypeTXT, testHTML) != nil || checkInvoiceFileType(FileTypeHTML, []byte("Invoice")) != nil {
+		t.Error()
+	}
+
+	err := checkInvoiceFileType(FileTypePDF, testPNG)
+
+	if err == nil || err.Error() != "FileType is \"pdf\" but the file is an image (image/png)" {
+		t.Error(err)
+	}
+
+	err = checkInvoiceFileType(FileTypeDOC, testDOCX)
+
+	if err == nil || err.Error() != "FileType is \"doc\" but the file is a zip archive, such as a docx or xlsx file, which is not supported" {
+		t.Error(err)
+	}
+
+	err = checkInvoiceFileType("docx", testDOCX)
+
+	if err == nil || err.Error() != "FileType \"docx\" is not supported" {
+		t.Error(err)
+	}
+
+	err = checkInvoiceFileType("png", testPNG)
+
+	if err == nil || err.Error() != "FileType \"png\" is not supported" {
+		t.Error(err)
+	}
+}
+
+func Test_validateInvoiceRequest_DetectsFileType(t *testing.T) {
+	request := applyInvoiceDefaults(InvoiceRequest{ApplicationToken: "test", FileData: testPDF})
+
+	if request.FileType != FileTypePDF || validateInvoiceRequest(request) != nil {
+		t.Error(request.FileType)
+	}
+
+	request = applyInvoiceDefaults(InvoiceRequest{ApplicationToken: "test", FileType: "PDF", FileData: testPDF})
+
+	if request.FileType != FileTypePDF {
+		t.Error(request.FileType)
+	}
+
+	request = applyInvoiceDefaults(InvoiceRequest{ApplicationToken: "test", FileData: testOLE})
+	err := validateInvoiceRequest(request)
+
+	if err == nil || !strings.Contains(err.Error(), "couldn't be detected") {
+		t.Error(err)
+	}
+
+	request = applyInvoiceDefaults(InvoiceRequest{ApplicationToken: "test", FileType: FileTypePDF, FileData: testPNG})
+
+	if validateInvoiceRequest(request) == nil {
+		t.Error()
+	}
+}
+
+func Test_InvoiceStream_ChecksFileType(t *testing.T) {
+	if shouldRunIntegrationTests() {
+		return
+	}
+
+	client, received, requests := newInvoiceTestClient(t)
+
+	_, err := client.InvoiceStream(InvoiceStreamRequest{
+		ApplicationToken: "test",
+		FileType:         FileTypePDF,
+		File:             strings.NewReader(string(testPNG)),
+	})
+
+	if err == nil || !err.IsValidationFailedError || *requests != 0 {
+		t.Error(err)
+	}
+
+	_, err = client.InvoiceStream(InvoiceStreamRequest{
+		ApplicationToken: "test",
+		File:             strings.NewReader(string(testHTML)),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if received.Get("filetype") != "html" {
+		t.Error(received.Get("filetype"))
+	}
+}
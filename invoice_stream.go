@@ -0,0 +1,478 @@
+package pasdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// DefaultMaxInvoiceSize is the largest invoice file, in bytes, that a client will upload
+// unless it was created with WithMaxInvoiceSize.
+const DefaultMaxInvoiceSize = 25 * 1024 * 1024
+
+// InvoiceStreamRequest uploads an invoice for a completed application like InvoiceRequest,
+// but reads the file as it is sent rather than needing it all in memory, which is better
+// suited to large files.
+//
+// The size of the file is always checked against the client's maximum invoice size before
+// anything is sent. It can be determined in advance for FilePath and for readers such as
+// *os.File and *bytes.Reader. Other readers, such as standard input, are first copied to a
+// temporary file, stopping as soon as they turn out to be too large, and the invoice is
+// uploaded from there.
+type InvoiceStreamRequest struct {
+	ApplicationToken string          // The token you received when calling the "begin" endpoint.
+	FileType         InvoiceFileType // The file type, such as FileTypePDF. If this is empty, the type is detected from the file.
+	FilePath         string          // The path of the file to upload.
+	File             io.Reader       // The file to upload, if FilePath is empty. If the request has to be retried, File is read again from the start if it is an io.Seeker or was copied to a temporary file; otherwise the request isn't retried.
+}
+
+// Fetch executes the request using the credentials passed to Initialise.
+func (request InvoiceStreamRequest) Fetch() (*InvoiceResponse, *PASDKError) {
+	return defaultClient.InvoiceStreamContext(context.Background(), request)
+}
+
+// FetchContext executes the request using the credentials passed to Initialise. The
+// request is abandoned as soon as ctx is cancelled or its deadline passes.
+func (request InvoiceStreamRequest) FetchContext(ctx context.Context) (*InvoiceResponse, *PASDKError) {
+	return defaultClient.InvoiceStreamContext(ctx, request)
+}
+
+// Validate checks the request without sending it, and returns a validation error listing
+// every problem found in FieldErrors, or nil if the request is valid. The file itself isn't
+// read, so its size and contents are only checked when the request is sent.
+func (request InvoiceStreamRequest) Validate() *PASDKError {
+	return validateInvoiceStreamRequest(request)
+}
+
+// InvoiceStream executes the given request using this client's credentials.
+func (client *Client) InvoiceStream(request InvoiceStreamRequest) (*InvoiceResponse, *PASDKError) {
+	return client.InvoiceStreamContext(context.Background(), request)
+}
+
+// InvoiceStreamContext is like InvoiceStream, but the request is abandoned as soon as ctx
+// is cancelled or its deadline passes.
+func (client *Client) InvoiceStreamContext(ctx context.Context, request InvoiceStreamRequest) (response *InvoiceResponse, err *PASDKError) {
+	defer client.logFailure(ctx, "invoice", &err)
+	defer catchGenericPanic(&response, &err)
+
+	stream := &invoiceStream{client: client, request: request}
+	defer stream.removeSpoolFile()
+
+	validation := invoiceStreamValidation(request)
+
+	if _, known := knownInvoiceSize(request); len(request.FilePath) == 0 && request.File != nil && !known {
+		problem, spoolErr := stream.spool(client.maxInvoiceSize)
+
+		if spoolErr != nil {
+			return nil, spoolErr.Wrap("request is invalid: ")
+		}
+
+		validation.check(problem)
+	} else if len(request.FilePath) > 0 || request.File != nil {
+		validation.check(checkInvoiceSize(request, client.maxInvoiceSize))
+	}
+
+	err = validation.err()
+
+	if err == nil {
+		err = stream.readHead()
+	}
+
+	if err == nil {
+		stream.request.FileType = defaultInvoiceFileType(request.FileType, stream.head)
+		err = validateInvoiceStreamFileType(stream.request.FileType, stream.head)
+	}
+
+	if err != nil {
+		return nil, err.Wrap("request is invalid: ")
+	}
+
+	err = client.checkCredentialsExist()
+
+	if err != nil {
+		return nil, err.Wrap("API request failed: ")
+	}
+
+	requestURL, err := client.getRequestURL()
+
+	if err != nil {
+		return nil, err.Wrap("failed determining request URL: ")
+	}
+
+	client.logRequest(ctx, "invoice", "POST", []requestParam{
+		{"filetype", string(stream.request.FileType)},
+		{"token", request.ApplicationToken},
+	})
+
+	newRequest := func() (*http.Request, error) {
+		body, err := stream.open()
+
+		if err != nil {
+			return nil, err
+		}
+
+		httpRequest, err := http.NewRequestWithContext(ctx, "POST", requestURL+"invoice", body)
+
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+
+		httpRequest.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+		return httpRequest, nil
+	}
+
+	response, err = sendAPIRequest[InvoiceResponse](ctx, client, "invoice", newRequest)
+
+	if streamErr := stream.validationError(); streamErr != nil {
+		return nil, streamErr.Wrap("request is invalid: ")
+	}
+
+	if err != nil {
+		return nil, err.Wrap("API request failed: ")
+	}
+
+	return response, nil
+}
+
+func validateInvoiceStreamRequest(request InvoiceStreamRequest) *PASDKError {
+	validation := invoiceStreamValidation(request)
+
+	return validation.err()
+}
+
+// Checks the fields of the request that can be checked without reading the file.
+func invoiceStreamValidation(request InvoiceStreamRequest) validation {
+	validation := validation{}
+
+	validation.required("ApplicationToken", request.ApplicationToken)
+
+	if len(request.FilePath) == 0 && request.File == nil {
+		validation.fail("File", FieldErrorRequired, "either FilePath or File must be set")
+	}
+
+	if len(request.FileType) > 0 {
+		validation.check(checkInvoiceFileTypeSupported(defaultInvoiceFileType(request.FileType, nil)))
+	}
+
+	return validation
+}
+
+// Returns an error if the file type is missing or doesn't match head, the start of the file.
+func validateInvoiceStreamFileType(fileType InvoiceFileType, head []byte) *PASDKError {
+	if len(fileType) == 0 {
+		return buildFieldValidationError(FieldError{"FileType", FieldErrorRequired,
+			"FileType cannot be empty as the type of the file couldn't be detected"})
+	}
+
+	if problem := checkInvoiceFileType(fileType, head); problem != nil {
+		return buildFieldValidationError(*problem)
+	}
+
+	return nil
+}
+
+// Returns the size of the file to upload, or false if it can't be determined in advance.
+func knownInvoiceSize(request InvoiceStreamRequest) (int64, bool) {
+	if len(request.FilePath) > 0 {
+		info, err := os.Stat(request.FilePath)
+
+		if err != nil {
+			return 0, false
+		}
+
+		return info.Size(), true
+	}
+
+	if sized, ok := request.File.(interface{ Len() int }); ok {
+		return int64(sized.Len()), true
+	}
+
+	if file, ok := request.File.(*os.File); ok {
+		if info, err := file.Stat(); err == nil && info.Mode().IsRegular() {
+			return info.Size(), true
+		}
+	}
+
+	return 0, false
+}
+
+// Returns the problem with the file to upload if it is known to be empty or larger than
+// maxSize. Nothing is returned if the size can't be determined in advance, including when
+// FilePath can't be read, which is reported once the file is opened.
+func checkInvoiceSize(request InvoiceStreamRequest, maxSize int64) *FieldError {
+	size, known := knownInvoiceSize(request)
+
+	if !known {
+		return nil
+	}
+
+	return checkInvoiceSizeLimit(size, maxSize)
+}
+
+// Returns the problem with a file of the given size if it is empty or larger than maxSize.
+func checkInvoiceSizeLimit(size int64, maxSize int64) *FieldError {
+	if size == 0 {
+		return invoiceEmpty()
+	}
+
+	if size > maxSize {
+		return invoiceTooLarge(maxSize)
+	}
+
+	return nil
+}
+
+// Returns the problem with a file that is empty.
+func invoiceEmpty() *FieldError {
+	return &FieldError{"File", FieldErrorRequired, "the invoice file cannot be empty"}
+}
+
+// Returns the problem with a file that is larger than maxSize.
+func invoiceTooLarge(maxSize int64) *FieldError {
+	return &FieldError{"File", FieldErrorFileTooLarge, "the invoice file is larger than the maximum size of " +
+		toString(int(maxSize)) + " bytes"}
+}
+
+// Produces the body of an InvoiceStreamRequest, once for each attempt at sending it.
+type invoiceStream struct {
+	client  *Client
+	request InvoiceStreamRequest
+
+	mutex    sync.Mutex
+	attempts int
+	head     []byte        // The start of the file, which is read before sending to check its type.
+	spooled  string        // The path of the temporary file File was copied to, if its size wasn't known.
+	start    int64         // The offset File was at before it was first read, if it is an io.Seeker.
+	done     chan struct{} // Closed once the previous attempt has stopped reading the file.
+	err      *PASDKError   // Set if the file was found to be invalid while it was being sent.
+}
+
+// Copies File to a temporary file, stopping once it is larger than maxSize, and uploads
+// the invoice from there instead, so that nothing is sent if the file is too large.
+// Returns the problem with the file if it is empty or too large.
+func (stream *invoiceStream) spool(maxSize int64) (*FieldError, *PASDKError) {
+	spoolFile, err := os.CreateTemp("", "pasdk-invoice-*")
+
+	if err != nil {
+		return nil, buildUnexpectedError("failed creating temporary invoice file: " + err.Error()).withCause(err)
+	}
+
+	stream.spooled = spoolFile.Name()
+
+	size, copyErr := io.Copy(spoolFile, io.LimitReader(stream.request.File, maxSize+1))
+	closeErr := spoolFile.Close()
+
+	if copyErr != nil {
+		return nil, buildValidationFailedError("failed reading invoice file: " + copyErr.Error()).withCause(copyErr)
+	}
+
+	if closeErr != nil {
+		return nil, buildUnexpectedError("failed writing temporary invoice file: " + closeErr.Error()).withCause(closeErr)
+	}
+
+	stream.request.FilePath = stream.spooled
+	stream.request.File = nil
+
+	return checkInvoiceSizeLimit(size, maxSize), nil
+}
+
+// Deletes the temporary file made by spool, if there is one, once the last attempt at
+// sending it has finished reading it.
+func (stream *invoiceStream) removeSpoolFile() {
+	if len(stream.spooled) == 0 {
+		return
+	}
+
+	if stream.done != nil {
+		<-stream.done
+	}
+
+	os.Remove(stream.spooled)
+}
+
+// Reads the start of the file into head. When uploading File, head is sent before the
+// rest of File on the first attempt.
+func (stream *invoiceStream) readHead() *PASDKError {
+	file := stream.request.File
+
+	if len(stream.request.FilePath) > 0 {
+		opened, err := os.Open(stream.request.FilePath)
+
+		if err != nil {
+			return buildValidationFailedError("failed reading invoice file: " + err.Error()).withCause(err)
+		}
+
+		defer opened.Close()
+		file = opened
+	} else if seeker, seekable := file.(io.Seeker); seekable {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+
+		if err != nil {
+			return buildValidationFailedError("failed reading invoice file: " + err.Error()).withCause(err)
+		}
+
+		stream.start = start
+	}
+
+	head := make([]byte, fileTypeSniffLength)
+	size, err := io.ReadFull(file, head)
+
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return buildValidationFailedError("failed reading invoice file: " + err.Error()).withCause(err)
+	}
+
+	if size == 0 {
+		return buildFieldValidationError(*invoiceEmpty())
+	}
+
+	stream.head = head[:size]
+
+	return nil
+}
+
+// Returns a reader for the request body. The body is written by a separate goroutine as
+// it is read, so the file is never held in memory all at once.
+func (stream *invoiceStream) open() (io.ReadCloser, error) {
+	if stream.done != nil {
+		<-stream.done
+	}
+
+	stream.attempts++
+
+	file, err := stream.openFile()
+
+	if err != nil {
+		return nil, err
+	}
+
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	stream.done = done
+
+	go func() {
+		defer close(done)
+
+		if len(stream.request.FilePath) > 0 {
+			defer file.(io.Closer).Close()
+		}
+
+		writer.CloseWithError(stream.writeBody(writer, file))
+	}()
+
+	return reader, nil
+}
+
+// Returns the file to read for this attempt, positioned at its start.
+func (stream *invoiceStream) openFile() (io.Reader, error) {
+	if len(stream.request.FilePath) > 0 {
+		return os.Open(stream.request.FilePath)
+	}
+
+	if stream.attempts == 1 {
+		return io.MultiReader(bytes.NewReader(stream.head), stream.request.File), nil
+	}
+
+	seeker, seekable := stream.request.File.(io.Seeker)
+
+	if !seekable {
+		return nil, errors.New("the invoice can't be sent again because File has already been read and isn't an io.Seeker")
+	}
+
+	if _, err := seeker.Seek(stream.start, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return stream.request.File, nil
+}
+
+// Writes the form-encoded request body to output. The file is base64-encoded and signed as
+// it is read, and as "filedata" is the first parameter in alphabetical order, the rest of the
+// parameters can be signed afterwards with the signature itself sent last.
+func (stream *invoiceStream) writeBody(output io.Writer, file io.Reader) error {
+	maxSize := stream.client.maxInvoiceSize
+	hasher := hmac.New(sha256.New, []byte(stream.client.credentials.APISecret))
+
+	if _, err := io.WriteString(output, "filedata="); err != nil {
+		return err
+	}
+
+	hasher.Write([]byte("FILEDATA="))
+
+	encoder := base64.NewEncoder(base64.StdEncoding, io.MultiWriter(hasher, queryEscapingWriter{output}))
+	size, err := io.Copy(encoder, io.LimitReader(file, maxSize+1))
+
+	if err != nil {
+		return err
+	}
+
+	if size == 0 {
+		return stream.fail(buildFieldValidationError(*invoiceEmpty()))
+	}
+
+	if size > maxSize {
+		return stream.fail(buildFieldValidationError(*invoiceTooLarge(maxSize)))
+	}
+
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	hasher.Write([]byte("&"))
+
+	// Alphabetically sorted.
+	requestParams := []requestParam{
+		{"filetype", string(stream.request.FileType)},
+		{"token", stream.request.ApplicationToken},
+	}
+
+	writeSignedParams(hasher, requestParams)
+
+	requestParams = append(requestParams, requestParam{"api_key", stream.client.credentials.APIKey})
+	requestParams = append(requestParams, requestParam{"signature", hex.EncodeToString(hasher.Sum(nil))})
+
+	_, err = io.WriteString(output, "&"+encodeParams(requestParams))
+
+	return err
+}
+
+// Records that the file is invalid and returns the error to abandon the upload with.
+func (stream *invoiceStream) fail(err *PASDKError) error {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	stream.err = err
+
+	return err
+}
+
+// Returns the error the upload was abandoned with if the file turned out to be invalid.
+func (stream *invoiceStream) validationError() *PASDKError {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	return stream.err
+}
+
+// Writes everything URL-encoded to an underlying writer.
+type queryEscapingWriter struct {
+	writer io.Writer
+}
+
+func (writer queryEscapingWriter) Write(data []byte) (int, error) {
+	if _, err := io.WriteString(writer.writer, url.QueryEscape(string(data))); err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
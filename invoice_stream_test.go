@@ -0,0 +1,254 @@
+package pasdk
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_InvoiceStream(t *testing.T) {
+	if shouldRunIntegrationTests() {
+		return
+	}
+
+	request := InvoiceStreamRequest{
+		ApplicationToken: "aed3bd4e-c478-4d73-a6fa-3640a7155e4f",
+		FileType:         "txt",
+		File:             bytes.NewReader([]byte("Test invoice for £100")),
+	}
+
+	response, err := request.Fetch()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.UploadStatus != "success" {
+		t.Error()
+	}
+}
+
+// Returns a client whose server records the form it receives.
+func newInvoiceTestClient(t *testing.T, options ...ClientOption) (*Client, *url.Values, *int) {
+	received := &url.Values{}
+	requests := new(int)
+
+	server, _ := newTestServerClient(t, PAAuth{}, func(writer http.ResponseWriter, request *http.Request) {
+		*requests++
+		request.ParseForm()
+		*received = request.PostForm
+		writer.Write([]byte(`{"status":"ok","msg":null,"data":{"token":"test","upload_status":"success"}}`))
+	})
+
+	options = append([]ClientOption{WithHTTPClient(server.Client())}, options...)
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: server.URL}, options...)
+
+	return client, received, requests
+}
+
+func Test_InvoiceStream_StreamsFileAndSignature(t *testing.T) {
+	if shouldRunIntegrationTests() {
+		return
+	}
+
+	client, received, _ := newInvoiceTestClient(t)
+
+	// An odd length, so the encoded data ends in "=" padding.
+	fileData := make([]byte, 100001)
+	rand.Read(fileData)
+	copy(fileData, "%PDF-1.7\n")
+
+	// Hide the reader's Len method so the size isn't known in advance.
+	_, err := client.InvoiceStream(InvoiceStreamRequest{
+		ApplicationToken: "test",
+		FileType:         "pdf",
+		File:             io.MultiReader(bytes.NewReader(fileData)),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(fileData)
+
+	if received.Get("filedata") != encoded {
+		t.Error("file data doesn't match")
+	}
+
+	expectedSignature := generateSignature([]requestParam{
+		{"filedata", encoded},
+		{"filetype", "pdf"},
+		{"token", "test"},
+	}, "secret")
+
+	if received.Get("signature") != expectedSignature || received.Get("api_key") != "key" {
+		t.Error(received.Get("signature"))
+	}
+}
+
+func Test_InvoiceStream_ReadsFilePath(t *testing.T) {
+	if shouldRunIntegrationTests() {
+		return
+	}
+
+	client, received, _ := newInvoiceTestClient(t)
+
+	path := filepath.Join(t.TempDir(), "invoice.txt")
+
+	if err := os.WriteFile(path, []byte("Test invoice"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := client.InvoiceStream(InvoiceStreamRequest{ApplicationToken: "test", FileType: "txt", FilePath: path})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if received.Get("filedata") != base64.StdEncoding.EncodeToString([]byte("Test invoice")) {
+		t.Error(received.Get("filedata"))
+	}
+
+	_, err = client.InvoiceStream(InvoiceStreamRequest{ApplicationToken: "test", FileType: "txt",
+		FilePath: filepath.Join(t.TempDir(), "missing.txt")})
+
+	if err == nil || !err.IsValidationFailedError {
+		t.Error(err)
+	}
+}
+
+func Test_InvoiceStream_EnforcesMaxSize(t *testing.T) {
+	if shouldRunIntegrationTests() {
+		return
+	}
+
+	client, received, requests := newInvoiceTestClient(t, WithMaxInvoiceSize(10))
+
+	_, err := client.InvoiceStream(InvoiceStreamRequest{
+		ApplicationToken: "test",
+		FileType:         "txt",
+		File:             bytes.NewReader([]byte("Longer than ten bytes")),
+	})
+
+	if err == nil || !err.IsValidationFailedError {
+		t.Error(err)
+	}
+	if *requests != 0 {
+		t.Error("the request was sent")
+	}
+
+	// When the size isn't known in advance the file is still checked before it is sent.
+	_, err = client.InvoiceStream(InvoiceStreamRequest{
+		ApplicationToken: "test",
+		FileType:         "txt",
+		File:             io.MultiReader(bytes.NewReader([]byte("Longer than ten bytes"))),
+	})
+
+	if err == nil || len(err.FieldErrors) != 1 || err.FieldErrors[0].Field != "File" ||
+		err.FieldErrors[0].Code != FieldErrorFileTooLarge {
+		t.Error(err)
+	}
+	if *requests != 0 {
+		t.Error("the request was sent")
+	}
+
+	_, err = client.InvoiceStream(InvoiceStreamRequest{
+		ApplicationToken: "test",
+		FileType:         "txt",
+		File:             io.MultiReader(),
+	})
+
+	if err == nil || len(err.FieldErrors) != 1 || err.FieldErrors[0].Code != FieldErrorRequired {
+		t.Error(err)
+	}
+	if *requests != 0 {
+		t.Error("the request was sent")
+	}
+
+	_, err = client.InvoiceStream(InvoiceStreamRequest{
+		ApplicationToken: "test",
+		FileType:         "txt",
+		File:             io.MultiReader(bytes.NewReader([]byte("Invoice"))),
+	})
+
+	if err != nil || *requests != 1 || received.Get("filedata") != base64.StdEncoding.EncodeToString([]byte("Invoice")) {
+		t.Error(err)
+	}
+
+	_, err = client.Invoice(InvoiceRequest{ApplicationToken: "test", FileType: "txt", FileData: []byte("Longer than ten bytes")})
+
+	if err == nil || !err.IsValidationFailedError {
+		t.Error(err)
+	}
+}
+
+func Test_validateInvoiceStreamRequest(t *testing.T) {
+	request := InvoiceStreamRequest{}
+
+	if validateInvoiceStreamRequest(request).FieldErrors[0].Message != "ApplicationToken cannot be empty" {
+		t.Error()
+	}
+
+	request.ApplicationToken = "test"
+
+	if validateInvoiceStreamRequest(request).FieldErrors[0].Message != "either FilePath or File must be set" {
+		t.Error()
+	}
+
+	request.File = bytes.NewReader(nil)
+
+	if validateInvoiceStreamRequest(request) != nil {
+		t.Error()
+	}
+
+	if checkInvoiceSize(request, 10).Error() != "the invoice file cannot be empty" {
+		t.Error()
+	}
+}
+
+func Test_InvoiceStream_ReportsSizeWithOtherProblems(t *testing.T) {
+	if shouldRunIntegrationTests() {
+		return
+	}
+
+	client, _, requests := newInvoiceTestClient(t, WithMaxInvoiceSize(10))
+
+	_, err := client.InvoiceStream(InvoiceStreamRequest{
+		FileType: "png",
+		File:     bytes.NewReader([]byte("Longer than ten bytes")),
+	})
+
+	if err == nil || len(err.FieldErrors) != 3 || *requests != 0 {
+		t.Fatal(err)
+	}
+	if err.FieldErrors[0].Code != FieldErrorRequired || err.FieldErrors[1].Code != FieldErrorUnsupportedFileType ||
+		err.FieldErrors[2].Code != FieldErrorFileTooLarge {
+		t.Error(err.FieldErrors)
+	}
+
+	_, err = client.Invoice(InvoiceRequest{FileType: "txt", FileData: []byte("Longer than ten bytes")})
+
+	if err == nil || len(err.FieldErrors) != 2 || err.FieldErrors[1].Code != FieldErrorFileTooLarge {
+		t.Error(err)
+	}
+}
+
+func Test_WithMaxInvoiceSize_UsesDefault_WhenNotPositive(t *testing.T) {
+	for _, size := range []int64{0, -1} {
+		client := NewClient(PAAuth{}, WithMaxInvoiceSize(size))
+
+		if client.maxInvoiceSize != DefaultMaxInvoiceSize {
+			t.Error(size, client.maxInvoiceSize)
+		}
+
+		if checkInvoiceSize(InvoiceStreamRequest{File: bytes.NewReader([]byte("Invoice"))}, client.maxInvoiceSize) != nil {
+			t.Error(size)
+		}
+	}
+}
@@ -32,19 +32,19 @@ func Test_Invoice(t *testing.T) {
 func Test_validateInvoiceRequest(t *testing.T) {
 	request := InvoiceRequest{}
 
-	if validateInvoiceRequest(request).Error() != "ApplicationToken cannot be empty" {
+	if validateInvoiceRequest(request).FieldErrors[0].Message != "ApplicationToken cannot be empty" {
 		t.Error()
 	}
 
 	request.ApplicationToken = "test"
 
-	if validateInvoiceRequest(request).Error() != "FileType cannot be empty" {
-		t.Error(validateInvoiceRequest(request).Error())
+	if validateInvoiceRequest(request).FieldErrors[0].Message != "FileType cannot be empty" {
+		t.Error(validateInvoiceRequest(request))
 	}
 
-	request.FileType = "test"
+	request.FileType = "txt"
 
-	if validateInvoiceRequest(request).Error() != "FileData cannot be empty" {
+	if validateInvoiceRequest(request).FieldErrors[0].Message != "FileData cannot be empty" {
 		t.Error()
 	}
 
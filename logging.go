@@ -0,0 +1,165 @@
+package pasdk
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Logger receives the SDK's log messages. It is satisfied by *slog.Logger, so you can
+// pass one directly to WithLogger, but any type with these methods will do. Arguments
+// are given as alternating keys and values, as with slog.
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
+}
+
+// WithLogger makes the client log every request and response at debug level, and every
+// failed request at error level along with the type of error. The API key, signature
+// and the customer's personal details are never logged.
+func WithLogger(logger Logger) ClientOption {
+	return func(client *Client) {
+		client.logger = logger
+	}
+}
+
+// The value logged in place of redacted parameters.
+const redactedValue = "[REDACTED]"
+
+// Parameters whose values are never logged or recorded, because they are secret or
+// contain the customer's personal details.
+var sensitiveParamKeys = map[string]bool{
+	"api_key":   true,
+	"signature": true,
+	"f_name":    true,
+	"s_name":    true,
+	"addr1":     true,
+	"addr2":     true,
+	"addr3":     true,
+	"town":      true,
+	"county":    true,
+	"postcode":  true,
+	"dob":       true,
+	"email":     true,
+	"telephone": true,
+	"reg_no":    true,
+}
+
+// Parameters that hold URLs, whose query strings may contain secrets such as the one
+// checked by webhook.Handler.RequireSecret.
+var urlParamKeys = map[string]bool{
+	"webhook_url": true,
+	"success_url": true,
+	"failure_url": true,
+}
+
+// IsSensitiveParam returns true if the API request parameter with the given name, such as
+// "postcode", is secret or contains the customer's personal details, so shouldn't be
+// logged or stored. The SDK's own logging and the cassette package use the same list.
+func IsSensitiveParam(key string) bool {
+	return sensitiveParamKeys[key]
+}
+
+// RedactParam returns the value of the API request parameter with the given name in a form
+// that is safe to log or store. The values of sensitive parameters are replaced with
+// replacement, as are the values in the query strings of URL parameters such as
+// "webhook_url", which may contain secrets. The SDK's own logging and the cassette package
+// both use this.
+func RedactParam(key string, value string, replacement string) string {
+	if IsSensitiveParam(key) {
+		return replacement
+	}
+
+	if !urlParamKeys[key] || !strings.ContainsAny(value, "?@") {
+		return value
+	}
+
+	parsed, err := url.Parse(value)
+
+	if err != nil {
+		return replacement
+	}
+
+	if parsed.User != nil {
+		parsed.User = url.User(replacement)
+	}
+
+	if len(parsed.RawQuery) > 0 {
+		query := parsed.Query()
+
+		for name := range query {
+			query[name] = []string{replacement}
+		}
+
+		parsed.RawQuery = query.Encode()
+	}
+
+	return parsed.String()
+}
+
+// Returns the parameters as a map that is safe to log. Invoice file data is replaced
+// by its length, as it is far too large to be useful in a log.
+func redactParams(params []requestParam) map[string]string {
+	output := make(map[string]string, len(params))
+
+	for _, param := range params {
+		switch {
+		case param.key == "filedata":
+			output[param.key] = "[" + strconv.Itoa(len(param.value)) + " characters]"
+		default:
+			output[param.key] = RedactParam(param.key, param.value, redactedValue)
+		}
+	}
+
+	return output
+}
+
+// Logs a request that is about to be sent.
+func (client *Client) logRequest(ctx context.Context, endpointName string, method string, params []requestParam) {
+	if client.logger == nil {
+		return
+	}
+
+	client.logger.DebugContext(ctx, "pasdk: sending API request",
+		"endpoint", endpointName,
+		"method", method,
+		"params", redactParams(params))
+}
+
+// Logs the outcome of a single attempt at sending a request.
+func (client *Client) logResponse(ctx context.Context, endpointName string, attempt int, statusCode int,
+	duration time.Duration, err *PASDKError) {
+	if client.logger == nil {
+		return
+	}
+
+	args := []any{
+		"endpoint", endpointName,
+		"attempt", attempt,
+		"status_code", statusCode,
+		"duration", duration,
+	}
+
+	if err != nil {
+		args = append(args, "error_type", err.GetErrorType(), "api_message", err.APIMessage)
+	}
+
+	client.logger.DebugContext(ctx, "pasdk: received API response", args...)
+}
+
+// Logs err at error level if it isn't nil. This is deferred by each endpoint, so that
+// requests that fail validation are logged as well as those the API rejects.
+func (client *Client) logFailure(ctx context.Context, endpointName string, err **PASDKError) {
+	if client.logger == nil || *err == nil {
+		return
+	}
+
+	client.logger.ErrorContext(ctx, "pasdk: API request failed",
+		"endpoint", endpointName,
+		"error_type", (*err).GetErrorType(),
+		"status_code", (*err).StatusCode,
+		"api_message", (*err).APIMessage,
+		"error", (*err).Error())
+}
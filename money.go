@@ -0,0 +1,205 @@
+package pasdk
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount of money in pence, which is how the API represents all amounts.
+// It is sent to and received from the API as a whole number of pence, so £12.34 is
+// Money(1234). Use ParseMoney to convert from pounds and String to display an amount.
+type Money int
+
+// Pounds returns the given whole number of pounds as Money. It panics if the amount is
+// too large to be held in pence, as that can only be a mistake in the calling code.
+func Pounds(pounds int) Money {
+	if pounds > math.MaxInt/100 || pounds < math.MinInt/100 {
+		panic("pasdk: " + strconv.Itoa(pounds) + " pounds is too large to be an amount of money")
+	}
+
+	return Money(pounds * 100)
+}
+
+// ParseMoney parses an amount of pounds such as "£12.34", "12.34", "1,250" or "-£0.50"
+// into Money. At most two decimal places are allowed, so amounts are never rounded.
+// Commas are only allowed as thousands separators, so "1,250,000" is valid but "12,50"
+// isn't.
+func ParseMoney(text string) (Money, *PASDKError) {
+	original := text
+	text = strings.TrimSpace(text)
+
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+	text = strings.TrimPrefix(text, "£")
+
+	pounds, pence, hasDecimalPoint := strings.Cut(text, ".")
+
+	if len(pounds) == 0 || (hasDecimalPoint && (len(pence) == 0 || len(pence) > 2)) {
+		return 0, buildValidationFailedError("\"" + original + "\" is not a valid amount of money")
+	}
+
+	if strings.Contains(pounds, ",") {
+		if !isGroupedInThousands(pounds) {
+			return 0, buildValidationFailedError("\"" + original + "\" is not a valid amount of money")
+		}
+
+		pounds = strings.ReplaceAll(pounds, ",", "")
+	}
+
+	for len(pence) < 2 {
+		pence += "0"
+	}
+
+	if !isDigits(pounds) || !isDigits(pence) {
+		return 0, buildValidationFailedError("\"" + original + "\" is not a valid amount of money")
+	}
+
+	value, err := strconv.ParseInt(pounds+pence, 10, strconv.IntSize)
+
+	if err != nil {
+		return 0, buildValidationFailedError("\"" + original + "\" is too large to be an amount of money")
+	}
+
+	if negative {
+		value = -value
+	}
+
+	return Money(value), nil
+}
+
+// Returns true if the commas in text separate it into groups of three digits, apart from
+// the first group, which may have one to three digits, as in "1,250,000".
+func isGroupedInThousands(text string) bool {
+	groups := strings.Split(text, ",")
+
+	if len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return false
+	}
+
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isDigits(text string) bool {
+	for _, character := range text {
+		if character < '0' || character > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Pence returns the amount as a whole number of pence.
+func (money Money) Pence() int {
+	return int(money)
+}
+
+// String formats the amount in pounds, for example "£1,234.50" or "-£0.99".
+func (money Money) String() string {
+	sign := ""
+	pence := int64(money)
+
+	if pence < 0 {
+		sign = "-"
+	}
+
+	// Work with an unsigned value so the smallest possible int can be negated.
+	absolute := uint64(pence)
+
+	if pence < 0 {
+		absolute = uint64(-(pence + 1)) + 1
+	}
+
+	pounds := strconv.FormatUint(absolute/100, 10)
+	remainder := strconv.FormatUint(absolute%100, 10)
+
+	if len(remainder) < 2 {
+		remainder = "0" + remainder
+	}
+
+	var grouped strings.Builder
+
+	for i, digit := range pounds {
+		if i > 0 && (len(pounds)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+
+		grouped.WriteRune(digit)
+	}
+
+	return sign + "£" + grouped.String() + "." + remainder
+}
+
+// Add returns the sum of the two amounts, or an error if the result would overflow.
+func (money Money) Add(other Money) (Money, *PASDKError) {
+	result := money + other
+
+	if (other > 0 && result < money) || (other < 0 && result > money) {
+		return 0, buildValidationFailedError("adding " + other.String() + " to " + money.String() + " overflows")
+	}
+
+	return result, nil
+}
+
+// Sub returns the result of subtracting other from this amount, or an error if the
+// result would overflow.
+func (money Money) Sub(other Money) (Money, *PASDKError) {
+	result := money - other
+
+	if (other > 0 && result > money) || (other < 0 && result < money) {
+		return 0, buildValidationFailedError("subtracting " + other.String() + " from " + money.String() + " overflows")
+	}
+
+	return result, nil
+}
+
+// Multiply returns this amount multiplied by a whole number, or an error if the result
+// would overflow.
+func (money Money) Multiply(multiplier int) (Money, *PASDKError) {
+	if money == 0 || multiplier == 0 {
+		return 0, nil
+	}
+
+	result := money * Money(multiplier)
+
+	if result/Money(multiplier) != money || (money == Money(math.MinInt) && multiplier == -1) {
+		return 0, buildValidationFailedError("multiplying " + money.String() + " by " + toString(multiplier) + " overflows")
+	}
+
+	return result, nil
+}
+
+// Split divides this amount into the given number of instalments that add up to exactly
+// this amount. Any pence that can't be divided evenly are added to the earliest
+// instalments, one each, so no instalment differs from another by more than a penny.
+func (money Money) Split(instalments int) ([]Money, *PASDKError) {
+	if instalments <= 0 {
+		return nil, buildValidationFailedError("the number of instalments must be greater than 0")
+	}
+
+	if money < 0 {
+		return nil, buildValidationFailedError("a negative amount can't be split into instalments")
+	}
+
+	base := money / Money(instalments)
+	remainder := int(money % Money(instalments))
+
+	output := make([]Money, instalments)
+
+	for i := range output {
+		output[i] = base
+
+		if i < remainder {
+			output[i]++
+		}
+	}
+
+	return output, nil
+}
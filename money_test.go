@@ -0,0 +1,165 @@
+package pasdk
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func Test_ParseMoney(t *testing.T) {
+	tests := map[string]Money{
+		"£12.34":    1234,
+		"12.34":     1234,
+		"12.3":      1230,
+		"12":        1200,
+		" £0.05 ":   5,
+		"£1,234.56": 123456,
+		"1,250,000": 125000000,
+		"999,999":   99999900,
+		"-£0.50":    -50,
+		"0":         0,
+	}
+
+	for text, expected := range tests {
+		money, err := ParseMoney(text)
+
+		if err != nil {
+			t.Error(text, err)
+			continue
+		}
+		if money != expected {
+			t.Error(text, money.Pence())
+		}
+	}
+
+	for _, text := range []string{"", "£", "12.345", "12.", ".50", "£12.3a", "twelve", "1e5", "£-1", "99999999999999999999",
+		"1,2,3", ",,5", ",500", "1,", "12,50", "1,2345", "1234,567", "1,234.5,6"} {
+		_, err := ParseMoney(text)
+
+		if err == nil || !err.IsValidationFailedError {
+			t.Error(text)
+		}
+	}
+}
+
+func Test_Pounds_PanicsOnOverflow(t *testing.T) {
+	if Pounds(12) != 1200 || Pounds(-3) != -300 {
+		t.Error()
+	}
+
+	for _, pounds := range []int{math.MaxInt / 10, math.MinInt / 10} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error(pounds)
+				}
+			}()
+
+			Pounds(pounds)
+		}()
+	}
+}
+
+func Test_Money_String(t *testing.T) {
+	tests := map[Money]string{
+		0:         "£0.00",
+		5:         "£0.05",
+		1234:      "£12.34",
+		100000:    "£1,000.00",
+		123456789: "£1,234,567.89",
+		-99:       "-£0.99",
+	}
+
+	for money, expected := range tests {
+		if money.String() != expected {
+			t.Error(money.String())
+		}
+	}
+
+	if Money(math.MinInt64).String() != "-£92,233,720,368,547,758.08" {
+		t.Error(Money(math.MinInt64).String())
+	}
+}
+
+func Test_Money_Arithmetic(t *testing.T) {
+	sum, err := Money(150).Add(250)
+
+	if err != nil || sum != 400 {
+		t.Error()
+	}
+
+	difference, err := Money(150).Sub(250)
+
+	if err != nil || difference != -100 {
+		t.Error()
+	}
+
+	product, err := Money(150).Multiply(3)
+
+	if err != nil || product != 450 {
+		t.Error()
+	}
+
+	if _, err = Money(math.MaxInt).Add(1); err == nil {
+		t.Error()
+	}
+	if _, err = Money(math.MinInt).Sub(1); err == nil {
+		t.Error()
+	}
+	if _, err = Money(math.MaxInt / 2).Multiply(3); err == nil {
+		t.Error()
+	}
+	if _, err = Money(math.MinInt).Multiply(-1); err == nil {
+		t.Error()
+	}
+}
+
+func Test_Money_Split_DoesntDrift(t *testing.T) {
+	instalments, err := Money(10000).Split(3)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if instalments[0] != 3334 || instalments[1] != 3333 || instalments[2] != 3333 {
+		t.Error(instalments)
+	}
+
+	for amount := Money(1); amount < 2000; amount += 7 {
+		for count := 1; count <= 12; count++ {
+			instalments, _ := amount.Split(count)
+			total := Money(0)
+
+			for _, instalment := range instalments {
+				total += instalment
+			}
+
+			if total != amount {
+				t.Error(amount, count)
+			}
+		}
+	}
+
+	if _, err = Money(100).Split(0); err == nil {
+		t.Error()
+	}
+}
+
+func Test_Money_JSON_UsesPence(t *testing.T) {
+	var repayment Repayment
+
+	err := json.Unmarshal([]byte(`{"date":"2019-07-24","amount":12500}`), &repayment)
+
+	if err != nil || repayment.Amount != Pounds(125) {
+		t.Error(err)
+	}
+
+	output, _ := json.Marshal(struct {
+		Amount Money `json:"amount"`
+	}{Amount: 1234})
+
+	if string(output) != `{"amount":1234}` {
+		t.Error(string(output))
+	}
+}
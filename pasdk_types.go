@@ -25,9 +25,47 @@ type PASDKError struct {
 	// recieve this kind of error.
 	IsUnexpectedError bool
 
+	// IsCancelledError is true if the request was abandoned because the context passed
+	// to it was cancelled or its deadline passed. The API may or may not have received
+	// the request, so check the state of anything it might have changed before retrying.
+	IsCancelledError bool
+
+	// IsThrottledError is true if the API refused the request because too many requests
+	// were sent to it (a 429 response). IsRequestRefusedError is also set. Unlike other
+	// refused requests, the same request is likely to succeed if sent again later; see
+	// RetryAfter for how long the API asked you to wait.
+	IsThrottledError bool
+
+	Endpoint   string // The API endpoint the request was sent to, such as "begin". This is empty if the request failed before it could be sent.
+	StatusCode int    // The HTTP status code returned by the API, or 0 if no response was received.
+	APIMessage string // The error message returned by the API in its "msg" field, if any.
+	RawBody    string // The raw body of the API's response, if a response was received.
+	Cause      error  // The underlying error that caused this one, if any, such as a connection error.
+
+	FieldErrors []FieldError // Every problem found with the request's fields, if IsValidationFailedError is set because of them. The error message lists them all, separated by semicolons.
+
+	RetryAfter time.Duration // How long the API asked you to wait before sending another request, from the Retry-After header of a throttled response, or 0 if it didn't say.
+
 	errorMessage string
 }
 
+var (
+	// ErrRequestRefused matches any PASDKError with IsRequestRefusedError set when used with errors.Is.
+	ErrRequestRefused = errors.New("pasdk: the API refused the request")
+
+	// ErrValidationFailed matches any PASDKError with IsValidationFailedError set when used with errors.Is.
+	ErrValidationFailed = errors.New("pasdk: the request failed validation")
+
+	// ErrUnexpected matches any PASDKError with IsUnexpectedError set when used with errors.Is.
+	ErrUnexpected = errors.New("pasdk: an unexpected error occurred")
+
+	// ErrCancelled matches any PASDKError with IsCancelledError set when used with errors.Is.
+	ErrCancelled = errors.New("pasdk: the request was cancelled")
+
+	// ErrThrottled matches any PASDKError with IsThrottledError set when used with errors.Is.
+	ErrThrottled = errors.New("pasdk: the API is rate limiting requests")
+)
+
 // Wrap wraps the error message in this error with another error message.
 func (err *PASDKError) Wrap(errorString string) *PASDKError {
 	err.errorMessage = errorString + err.errorMessage
@@ -39,9 +77,36 @@ func (err PASDKError) Error() string {
 	return err.errorMessage
 }
 
+// Unwrap returns the underlying cause of this error, if any.
+func (err PASDKError) Unwrap() error {
+	return err.Cause
+}
+
+// Is reports whether this error matches target, which allows errors.Is to be used with
+// ErrRequestRefused, ErrValidationFailed, ErrUnexpected, ErrCancelled and ErrThrottled.
+func (err PASDKError) Is(target error) bool {
+	switch target {
+	case ErrRequestRefused:
+		return err.IsRequestRefusedError
+	case ErrValidationFailed:
+		return err.IsValidationFailedError
+	case ErrUnexpected:
+		return err.IsUnexpectedError
+	case ErrCancelled:
+		return err.IsCancelledError
+	case ErrThrottled:
+		return err.IsThrottledError
+	}
+
+	return false
+}
+
 // GetErrorType returns the type of error as a string. You may find this helpful
 // for debugging/logging purposes.
 func (err PASDKError) GetErrorType() string {
+	if err.IsThrottledError {
+		return "ThrottledError"
+	}
 	if err.IsRequestRefusedError {
 		return "RequestRefusedError"
 	}
@@ -51,6 +116,9 @@ func (err PASDKError) GetErrorType() string {
 	if err.IsUnexpectedError {
 		return "UnexpectedError"
 	}
+	if err.IsCancelledError {
+		return "CancelledError"
+	}
 
 	return ""
 }
@@ -63,10 +131,10 @@ type Plan struct {
 	DepositRequired    bool   `json:"deposit"`              // Whether a deposit is required by this plan (first payment taken immediately).
 	APR                string `json:"apr"`                  // The annual percentage interest rate of this plan.
 	Frequency          string `json:"frequency"`            // How often payments are made on this plan.
-	MinAmount          *int   `json:"min_amount"`           // The minimum amount allowed under this plan in pence, if any.
-	MaxAmount          *int   `json:"max_amount"`           // The maximum amount allowed under this plan in pence, if any.
+	MinAmount          *Money `json:"min_amount"`           // The minimum amount allowed under this plan in pence, if any.
+	MaxAmount          *Money `json:"max_amount"`           // The maximum amount allowed under this plan in pence, if any.
 	CommissionRate     string `json:"commission_rate"`      // The Payment Assist commission rate charged under this plan as a percentage.
-	CommissionFixedFee *int   `json:"commission_fixed_fee"` // The Payment Assist fixed commission fee charged under this plan in pence.
+	CommissionFixedFee *Money `json:"commission_fixed_fee"` // The Payment Assist fixed commission fee charged under this plan in pence.
 }
 
 func (plan *Plan) UnmarshalJSON(data []byte) error {
@@ -92,7 +160,7 @@ func (plan *Plan) UnmarshalJSON(data []byte) error {
 
 type Repayment struct {
 	Date   time.Time `json:"date"`   // The due date of this repayment.
-	Amount int       `json:"amount"` // The amount of this repayment, in pence.
+	Amount Money     `json:"amount"` // The amount of this repayment, in pence.
 }
 
 func (repayment *Repayment) UnmarshalJSON(data []byte) error {
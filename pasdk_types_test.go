@@ -1,7 +1,10 @@
 package pasdk
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -29,3 +32,46 @@ func Test_Repayment_UnmarshalJSON_UsesCorrectDateFormat(t *testing.T) {
 		t.Error()
 	}
 }
+
+func Test_PASDKError_Is_MatchesSentinelErrors(t *testing.T) {
+	var err error = buildRequestRefusedError("refused")
+
+	if !errors.Is(err, ErrRequestRefused) {
+		t.Error()
+	}
+	if errors.Is(err, ErrValidationFailed) || errors.Is(err, ErrUnexpected) || errors.Is(err, ErrCancelled) {
+		t.Error()
+	}
+
+	if !errors.Is(buildValidationFailedError("invalid"), ErrValidationFailed) {
+		t.Error()
+	}
+	if !errors.Is(buildUnexpectedError("unexpected"), ErrUnexpected) {
+		t.Error()
+	}
+	if !errors.Is(buildCancelledError("cancelled"), ErrCancelled) {
+		t.Error()
+	}
+}
+
+func Test_PASDKError_Unwrap_ReturnsCause(t *testing.T) {
+	var err error = fmt.Errorf("checkout failed: %w",
+		buildCancelledError("cancelled").withCause(context.DeadlineExceeded))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error()
+	}
+	if !errors.Is(err, ErrCancelled) {
+		t.Error()
+	}
+
+	var paErr *PASDKError
+
+	if !errors.As(err, &paErr) {
+		t.Error()
+		return
+	}
+	if !paErr.IsCancelledError {
+		t.Error()
+	}
+}
@@ -0,0 +1,230 @@
+package pasdktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+)
+
+// Start simulates the customer opening the application, moving it to "in_progress".
+func (server *Server) Start(token string) error {
+	return server.transition(token, func(application *Application) error {
+		return server.moveTo(application, pasdk.StatusInProgress)
+	})
+}
+
+// Approve simulates the customer being approved. If the application was begun with
+// auto-capture disabled it moves to "pending_capture", otherwise it is completed.
+func (server *Server) Approve(token string) error {
+	return server.transition(token, func(application *Application) error {
+		if application.Status == pasdk.StatusPending {
+			if err := server.moveTo(application, pasdk.StatusInProgress); err != nil {
+				return err
+			}
+		}
+
+		if !application.AutoCapture {
+			return server.moveTo(application, pasdk.StatusPendingCapture)
+		}
+
+		if err := server.moveTo(application, pasdk.StatusCompleted); err != nil {
+			return err
+		}
+
+		server.complete(application)
+
+		return nil
+	})
+}
+
+// Complete simulates an application being completed, whether or not it was waiting to
+// be captured.
+func (server *Server) Complete(token string) error {
+	return server.transition(token, func(application *Application) error {
+		if application.Status == pasdk.StatusPending {
+			if err := server.moveTo(application, pasdk.StatusInProgress); err != nil {
+				return err
+			}
+		}
+
+		if err := server.moveTo(application, pasdk.StatusCompleted); err != nil {
+			return err
+		}
+
+		server.complete(application)
+
+		return nil
+	})
+}
+
+// Decline simulates the customer being declined.
+func (server *Server) Decline(token string) error {
+	return server.transition(token, func(application *Application) error {
+		return server.moveTo(application, pasdk.StatusDeclined)
+	})
+}
+
+// Expire makes the application expire immediately.
+func (server *Server) Expire(token string) error {
+	return server.transition(token, func(application *Application) error {
+		if err := server.moveTo(application, pasdk.StatusExpired); err != nil {
+			return err
+		}
+
+		application.ExpiresAt = server.now()
+
+		return nil
+	})
+}
+
+// Applies change to an application and sends a webhook if its status changed.
+func (server *Server) transition(token string, change func(*Application) error) error {
+	server.mutex.Lock()
+
+	application, exists := server.applications[token]
+
+	if !exists {
+		server.mutex.Unlock()
+		return errors.New("application " + token + " does not exist")
+	}
+
+	server.expireIfDue(application)
+
+	previousStatus := application.Status
+	err := change(application)
+
+	if err == nil && application.Status != previousStatus {
+		server.queueWebhook(buildWebhookEvent(*application))
+	}
+
+	server.mutex.Unlock()
+
+	return err
+}
+
+// Must be called with the mutex held.
+func (server *Server) moveTo(application *Application, status pasdk.ApplicationStatus) error {
+	if !application.Status.CanTransitionTo(status) {
+		return errors.New("application " + application.Token + " can't move from \"" +
+			string(application.Status) + "\" to \"" + string(status) + "\"")
+	}
+
+	application.Status = status
+	application.LastAccessedAt = server.now()
+
+	return nil
+}
+
+// Marks an application as completed. Must be called with the mutex held.
+func (server *Server) complete(application *Application) {
+	application.Status = pasdk.StatusCompleted
+	application.PaymentAssistReference = "PA" + strings.ToUpper(strings.ReplaceAll(application.Token, "-", "")[:10])
+	application.RequiresInvoice = true
+}
+
+// A webhook that is due to be sent.
+type webhookEvent struct {
+	url  string
+	body []byte
+}
+
+func buildWebhookEvent(application Application) webhookEvent {
+	body, _ := json.Marshal(map[string]interface{}{
+		"token":    application.Token,
+		"status":   application.Status,
+		"order_id": application.OrderID,
+		"pa_ref":   application.PaymentAssistReference,
+		"amount":   application.Amount,
+	})
+
+	return webhookEvent{url: application.WebhookURL, body: body}
+}
+
+// Sends a webhook in the background, if the application has a webhook URL, so that a
+// slow receiver, or one that calls back into the server, doesn't hold up the request that
+// caused it. Webhooks are sent one at a time, in the order they were queued.
+func (server *Server) queueWebhook(event webhookEvent) {
+	if len(event.url) == 0 {
+		return
+	}
+
+	server.webhookMutex.Lock()
+	defer server.webhookMutex.Unlock()
+
+	previous := server.lastWebhook
+	done := make(chan struct{})
+	server.lastWebhook = done
+	server.webhooks.Add(1)
+
+	go func() {
+		defer server.webhooks.Done()
+		defer close(done)
+
+		if previous != nil {
+			<-previous
+		}
+
+		sendWebhook(event)
+	}()
+}
+
+// Sends a webhook. Failures are ignored, as they are by the real API once it has given
+// up retrying.
+func sendWebhook(event webhookEvent) {
+	client := http.Client{Timeout: 10 * time.Second}
+
+	response, err := client.Post(event.url, "application/json", bytes.NewReader(event.body))
+
+	if err == nil {
+		response.Body.Close()
+	}
+}
+
+// Handles the admin API, which lets code outside the test process simulate customer
+// actions with requests such as "POST /admin/approve/<token>". The available actions are
+// start, approve, complete, decline and expire.
+func (server *Server) handleAdmin(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		writeError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(request.URL.Path, "/admin/"), "/")
+
+	if len(parts) != 2 || len(parts[1]) == 0 {
+		writeError(writer, http.StatusNotFound, "Expected a path like /admin/<action>/<token>")
+		return
+	}
+
+	actions := map[string]func(string) error{
+		"start":    server.Start,
+		"approve":  server.Approve,
+		"complete": server.Complete,
+		"decline":  server.Decline,
+		"expire":   server.Expire,
+	}
+
+	action, exists := actions[parts[0]]
+
+	if !exists {
+		writeError(writer, http.StatusNotFound, "Unknown action "+parts[0])
+		return
+	}
+
+	if err := action(parts[1]); err != nil {
+		writeError(writer, http.StatusConflict, err.Error())
+		return
+	}
+
+	application, _ := server.Application(parts[1])
+
+	writeData(writer, map[string]interface{}{
+		"token":  application.Token,
+		"status": application.Status,
+	})
+}
@@ -0,0 +1,422 @@
+package pasdktest
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+)
+
+// Applications expire after 24 hours unless told otherwise.
+const defaultExpiry = 24 * 60 * 60
+
+func (server *Server) handleAccount(writer http.ResponseWriter, params url.Values) {
+	server.mutex.Lock()
+	account := server.account
+	server.mutex.Unlock()
+
+	writeData(writer, account)
+}
+
+func (server *Server) handlePlan(writer http.ResponseWriter, params url.Values) {
+	amount, err := strconv.Atoi(params.Get("amount"))
+
+	if err != nil || amount <= 0 {
+		writeError(writer, http.StatusBadRequest, "Invalid amount")
+		return
+	}
+
+	server.mutex.Lock()
+	plan, found := server.findPlan(params.Get("plan_id"))
+	schedule, hasFixture := server.schedules[scheduleKey{plan.ID, pasdk.Money(amount)}]
+	now := server.now()
+	server.mutex.Unlock()
+
+	if !found {
+		writeError(writer, http.StatusBadRequest, "Invalid plan ID")
+		return
+	}
+
+	if !hasFixture {
+		if apr, err := strconv.ParseFloat(plan.APR, 64); len(plan.APR) > 0 && (err != nil || apr != 0) {
+			writeError(writer, http.StatusBadRequest, "No schedule set for plan "+strconv.Itoa(plan.ID)+
+				" and amount "+strconv.Itoa(amount)+"; set one with SetPlanSchedule")
+			return
+		}
+
+		var valid bool
+		schedule, valid = interestFreeSchedule(plan, pasdk.Money(amount), now)
+
+		if !valid {
+			writeError(writer, http.StatusBadRequest, "Invalid plan")
+			return
+		}
+	}
+
+	repayments := make([]map[string]interface{}, 0, len(schedule.PaymentSchedule))
+
+	for _, repayment := range schedule.PaymentSchedule {
+		repayments = append(repayments, map[string]interface{}{
+			"date":   repayment.Date.Format("2006-01-02"),
+			"amount": repayment.Amount,
+		})
+	}
+
+	writeData(writer, map[string]interface{}{
+		"plan":      schedule.PlanName,
+		"amount":    schedule.Amount,
+		"interest":  schedule.Interest,
+		"repayable": schedule.TotalRepayable,
+		"schedule":  repayments,
+	})
+}
+
+// Identifies a schedule set with SetPlanSchedule.
+type scheduleKey struct {
+	planID int
+	amount pasdk.Money
+}
+
+// Works out the schedule for an interest-free plan by splitting amount into equal payments,
+// with any spare pence taken in the earliest ones. The first payment is due on now if the
+// plan requires a deposit, otherwise one period later. This is worked out separately from
+// pasdk.CalculateSchedule so that tests of the SDK's calculator don't just compare it with
+// itself.
+func interestFreeSchedule(plan pasdk.Plan, amount pasdk.Money, now time.Time) (pasdk.PlanResponse, bool) {
+	if plan.Instalments <= 0 {
+		return pasdk.PlanResponse{}, false
+	}
+
+	daysPerPeriod := map[string]int{"weekly": 7, "fortnightly": 14, "monthly": 0}[plan.Frequency]
+
+	if daysPerPeriod == 0 && plan.Frequency != "monthly" {
+		return pasdk.PlanResponse{}, false
+	}
+
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	payment := int64(amount) / int64(plan.Instalments)
+	spare := int64(amount) % int64(plan.Instalments)
+	schedule := make([]pasdk.Repayment, plan.Instalments)
+
+	for i := range schedule {
+		period := i
+
+		if !plan.DepositRequired {
+			period++
+		}
+
+		date := start.AddDate(0, 0, daysPerPeriod*period)
+
+		if daysPerPeriod == 0 {
+			// Payments due on a day the month doesn't have are taken on its last day.
+			monthStart := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, period, 0)
+			daysInMonth := monthStart.AddDate(0, 1, -1).Day()
+			day := start.Day()
+
+			if day > daysInMonth {
+				day = daysInMonth
+			}
+
+			date = monthStart.AddDate(0, 0, day-1)
+		}
+
+		schedule[i] = pasdk.Repayment{Date: date, Amount: pasdk.Money(payment)}
+
+		if int64(i) < spare {
+			schedule[i].Amount++
+		}
+	}
+
+	return pasdk.PlanResponse{
+		PlanName:        plan.Name,
+		Amount:          amount,
+		TotalRepayable:  amount,
+		PaymentSchedule: schedule,
+	}, true
+}
+
+func (server *Server) handlePreapproval(writer http.ResponseWriter, params url.Values) {
+	for _, field := range []string{"f_name", "s_name", "addr1", "postcode"} {
+		if len(params.Get(field)) == 0 {
+			writeError(writer, http.StatusBadRequest, "Missing required field "+field)
+			return
+		}
+	}
+
+	server.mutex.Lock()
+	approved := server.preapprove
+	server.mutex.Unlock()
+
+	writeData(writer, map[string]interface{}{
+		"approved": approved,
+	})
+}
+
+func (server *Server) handleBegin(writer http.ResponseWriter, params url.Values) {
+	for _, field := range []string{"order_id", "amount", "f_name", "s_name", "addr1", "postcode"} {
+		if len(params.Get(field)) == 0 {
+			writeError(writer, http.StatusBadRequest, "Missing required field "+field)
+			return
+		}
+	}
+
+	amount, err := strconv.Atoi(params.Get("amount"))
+
+	if err != nil || amount <= 0 {
+		writeError(writer, http.StatusBadRequest, "Invalid amount")
+		return
+	}
+
+	expiry := defaultExpiry
+
+	if params.Has("expiry") {
+		expiry, err = strconv.Atoi(params.Get("expiry"))
+
+		if err != nil || expiry < 0 {
+			writeError(writer, http.StatusBadRequest, "Invalid expiry")
+			return
+		}
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	plan, found := server.findPlan(params.Get("plan_id"))
+
+	if !found {
+		writeError(writer, http.StatusBadRequest, "Invalid plan ID")
+		return
+	}
+
+	for _, application := range server.applications {
+		if application.OrderID == params.Get("order_id") {
+			writeError(writer, http.StatusBadRequest, "An application with this order ID already exists")
+			return
+		}
+	}
+
+	now := server.now()
+
+	application := &Application{
+		Token:           newToken(),
+		OrderID:         params.Get("order_id"),
+		Status:          pasdk.StatusPending,
+		Amount:          pasdk.Money(amount),
+		PlanID:          plan.ID,
+		AutoCapture:     params.Get("auto_capture") != "false",
+		ExpiresAt:       now.Add(time.Duration(expiry) * time.Second),
+		RequiresInvoice: false,
+		LastAccessedAt:  now,
+		WebhookURL:      params.Get("webhook_url"),
+		Params:          params,
+	}
+
+	server.applications[application.Token] = application
+
+	output := map[string]interface{}{
+		"token": application.Token,
+		"url":   server.URL + "apply/" + application.Token,
+	}
+
+	if params.Get("qr_code") == "true" {
+		output["qr_code"] = base64.StdEncoding.EncodeToString([]byte(application.Token))
+	}
+
+	writeData(writer, output)
+}
+
+func (server *Server) handleStatus(writer http.ResponseWriter, params url.Values) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	application, found := server.findApplication(writer, params)
+
+	if !found {
+		return
+	}
+
+	writeData(writer, map[string]interface{}{
+		"token":            application.Token,
+		"status":           application.Status,
+		"amount":           application.Amount,
+		"expires_at":       application.ExpiresAt.Format(time.RFC3339),
+		"pa_ref":           application.PaymentAssistReference,
+		"requires_invoice": application.RequiresInvoice,
+		"has_invoice":      application.HasInvoice,
+		"last_accessed_at": application.LastAccessedAt.Format(time.RFC3339),
+	})
+}
+
+func (server *Server) handleUpdate(writer http.ResponseWriter, params url.Values) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	application, found := server.findApplication(writer, params)
+
+	if !found {
+		return
+	}
+
+	output := map[string]interface{}{
+		"token": application.Token,
+	}
+
+	if params.Has("order_id") && !application.Status.CanUpdateOrderID() {
+		writeError(writer, http.StatusBadRequest, "The order ID can only be changed once the application is completed")
+		return
+	}
+
+	if (params.Has("amount") || params.Has("expiry")) && !application.Status.CanUpdateAmountOrExpiry() {
+		writeError(writer, http.StatusBadRequest, "The amount and expiry can't be changed for this application")
+		return
+	}
+
+	amount := int(application.Amount)
+	expiry := 0
+	var err error
+
+	if params.Has("amount") {
+		amount, err = strconv.Atoi(params.Get("amount"))
+
+		if err != nil || amount <= 0 || amount >= int(application.Amount) {
+			writeError(writer, http.StatusBadRequest, "The new amount must be less than the current amount")
+			return
+		}
+	}
+
+	if params.Has("expiry") {
+		expiry, err = strconv.Atoi(params.Get("expiry"))
+
+		if err != nil || expiry < 0 {
+			writeError(writer, http.StatusBadRequest, "Invalid expiry")
+			return
+		}
+	}
+
+	if params.Has("order_id") {
+		application.OrderID = params.Get("order_id")
+		output["order_id"] = application.OrderID
+	}
+
+	if params.Has("amount") {
+		application.Amount = pasdk.Money(amount)
+		output["amount"] = strconv.Itoa(amount)
+	}
+
+	if params.Has("expiry") {
+		application.ExpiresAt = server.now().Add(time.Duration(expiry) * time.Second)
+		output["expiry"] = strconv.Itoa(expiry)
+		server.expireIfDue(application)
+	}
+
+	writeData(writer, output)
+}
+
+func (server *Server) handleCapture(writer http.ResponseWriter, params url.Values) {
+	server.mutex.Lock()
+
+	application, found := server.findApplication(writer, params)
+
+	if !found {
+		server.mutex.Unlock()
+		return
+	}
+
+	if !application.Status.CanCapture() {
+		server.mutex.Unlock()
+		writeError(writer, http.StatusBadRequest, "Application is not awaiting capture")
+		return
+	}
+
+	server.complete(application)
+
+	output := map[string]interface{}{
+		"token":  application.Token,
+		"status": application.Status,
+	}
+
+	if plan, found := server.findPlan(strconv.Itoa(application.PlanID)); found && plan.DepositRequired {
+		output["deposit_captured"] = true
+	}
+
+	server.queueWebhook(buildWebhookEvent(*application))
+	server.mutex.Unlock()
+
+	writeData(writer, output)
+}
+
+func (server *Server) handleInvoice(writer http.ResponseWriter, params url.Values) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	application, found := server.findApplication(writer, params)
+
+	if !found {
+		return
+	}
+
+	if application.Status != pasdk.StatusCompleted {
+		writeError(writer, http.StatusBadRequest, "Application is not yet completed")
+		return
+	}
+
+	if len(params.Get("filetype")) == 0 {
+		writeError(writer, http.StatusBadRequest, "Missing required field filetype")
+		return
+	}
+
+	_, err := base64.StdEncoding.DecodeString(params.Get("filedata"))
+
+	if err != nil || len(params.Get("filedata")) == 0 {
+		writeData(writer, map[string]interface{}{
+			"token":         application.Token,
+			"upload_status": "failed",
+		})
+		return
+	}
+
+	application.HasInvoice = true
+
+	writeData(writer, map[string]interface{}{
+		"token":         application.Token,
+		"upload_status": "success",
+	})
+}
+
+// Returns the plan with the given ID, or the account's first plan if the ID is empty.
+// Must be called with the mutex held.
+func (server *Server) findPlan(planID string) (pasdk.Plan, bool) {
+	if len(planID) == 0 {
+		if len(server.account.Plans) == 0 {
+			return pasdk.Plan{}, false
+		}
+
+		return server.account.Plans[0], true
+	}
+
+	for _, plan := range server.account.Plans {
+		if strconv.Itoa(plan.ID) == planID {
+			return plan, true
+		}
+	}
+
+	return pasdk.Plan{}, false
+}
+
+// Returns the application with the token given in params, writing an error response if
+// there isn't one. Must be called with the mutex held.
+func (server *Server) findApplication(writer http.ResponseWriter, params url.Values) (*Application, bool) {
+	application, exists := server.applications[params.Get("token")]
+
+	if !exists {
+		writeError(writer, http.StatusNotFound, "Application not found")
+		return nil, false
+	}
+
+	server.expireIfDue(application)
+
+	return application, true
+}
@@ -0,0 +1,338 @@
+// Package pasdktest provides a fake Payment Assist API for testing code that uses the SDK,
+// without needing network access or demo credentials.
+//
+// The fake server implements all eight API endpoints, verifies request signatures and keeps
+// track of the applications created through it, so that "begin" creates a token, "status"
+// reflects later updates, and capture and expiry move applications between states. Methods
+// such as Approve and Decline simulate what the customer does on Payment Assist's side.
+package pasdktest
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+)
+
+// The credentials accepted by a server created with NewServer.
+const (
+	APIKey    = "pasdktest_api_key"
+	APISecret = "pasdktest_api_secret"
+)
+
+// Application is the state the fake server keeps for each application created through it.
+type Application struct {
+	Token                  string
+	OrderID                string
+	Status                 pasdk.ApplicationStatus
+	Amount                 pasdk.Money
+	PlanID                 int
+	AutoCapture            bool
+	ExpiresAt              time.Time
+	PaymentAssistReference string
+	RequiresInvoice        bool
+	HasInvoice             bool
+	LastAccessedAt         time.Time
+	WebhookURL             string
+	Params                 url.Values // The parameters the application was begun with.
+}
+
+// Server is a fake Payment Assist API. Create one with NewServer and close it with Close
+// once you are finished with it.
+type Server struct {
+	URL string // The base URL of the server, which should be used as the APIURL.
+
+	httpServer   *httptest.Server
+	mutex        sync.Mutex
+	applications map[string]*Application
+	account      pasdk.AccountResponse
+	schedules    map[scheduleKey]pasdk.PlanResponse
+	preapprove   bool
+	timeOffset   time.Duration
+
+	webhookMutex sync.Mutex
+	webhooks     sync.WaitGroup // Tracks the webhooks that are still being sent.
+	lastWebhook  chan struct{}  // Closed once the most recently queued webhook has been sent.
+}
+
+// NewServer starts a fake API that accepts the credentials APIKey and APISecret.
+func NewServer() *Server {
+	server := &Server{
+		applications: map[string]*Application{},
+		account:      defaultAccount(),
+		schedules:    map[scheduleKey]pasdk.PlanResponse{},
+		preapprove:   true,
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/account", server.requireMethod("GET", server.handleAccount))
+	mux.HandleFunc("/plan", server.requireMethod("POST", server.handlePlan))
+	mux.HandleFunc("/preapproval", server.requireMethod("POST", server.handlePreapproval))
+	mux.HandleFunc("/begin", server.requireMethod("POST", server.handleBegin))
+	mux.HandleFunc("/status", server.requireMethod("GET", server.handleStatus))
+	mux.HandleFunc("/update", server.requireMethod("POST", server.handleUpdate))
+	mux.HandleFunc("/capture", server.requireMethod("POST", server.handleCapture))
+	mux.HandleFunc("/invoice", server.requireMethod("POST", server.handleInvoice))
+	mux.HandleFunc("/admin/", server.handleAdmin)
+
+	server.httpServer = httptest.NewTLSServer(mux)
+	server.URL = server.httpServer.URL + "/"
+
+	return server
+}
+
+// Close waits for any webhooks that are still being sent, then shuts the server down.
+func (server *Server) Close() {
+	server.WaitForWebhooks()
+	server.httpServer.Close()
+}
+
+// WaitForWebhooks blocks until every webhook queued so far has been sent. Webhooks are
+// sent in the background, so use this before checking that your code received one.
+func (server *Server) WaitForWebhooks() {
+	server.webhooks.Wait()
+}
+
+// Credentials returns credentials that send requests to this server.
+func (server *Server) Credentials() pasdk.PAAuth {
+	return pasdk.PAAuth{
+		APIKey:    APIKey,
+		APISecret: APISecret,
+		APIURL:    server.URL,
+	}
+}
+
+// HTTPClient returns an http.Client that trusts the server's TLS certificate.
+func (server *Server) HTTPClient() *http.Client {
+	return server.httpServer.Client()
+}
+
+// Client returns a client that sends requests to this server. Any options are applied
+// after the ones needed to talk to the server.
+func (server *Server) Client(options ...pasdk.ClientOption) *pasdk.Client {
+	options = append([]pasdk.ClientOption{pasdk.WithHTTPClient(server.HTTPClient())}, options...)
+
+	return pasdk.NewClient(server.Credentials(), options...)
+}
+
+// SetAccount replaces the account details and plans returned by the "account" endpoint
+// and used by the "plan" and "begin" endpoints.
+func (server *Server) SetAccount(account pasdk.AccountResponse) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.account = account
+}
+
+// SetPlanSchedule sets the response the "plan" endpoint returns for the given plan and
+// amount, such as one recorded from the real API. Without one, the schedule for an
+// interest-free plan is worked out by splitting the amount into equal payments, and the
+// endpoint returns an error for plans with an APR above 0.
+func (server *Server) SetPlanSchedule(planID int, amount pasdk.Money, schedule pasdk.PlanResponse) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.schedules[scheduleKey{planID, amount}] = schedule
+}
+
+// SetPreapproval sets whether the "preapproval" endpoint approves customers. It does by default.
+func (server *Server) SetPreapproval(approved bool) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.preapprove = approved
+}
+
+// AdvanceTime moves the server's clock forward, which lets you test what happens when
+// applications expire without waiting.
+func (server *Server) AdvanceTime(duration time.Duration) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.timeOffset += duration
+}
+
+// Application returns a copy of the current state of an application.
+func (server *Server) Application(token string) (Application, bool) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	application, exists := server.applications[token]
+
+	if !exists {
+		return Application{}, false
+	}
+
+	server.expireIfDue(application)
+
+	return *application, true
+}
+
+// Applications returns a copy of the current state of every application.
+func (server *Server) Applications() []Application {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	output := make([]Application, 0, len(server.applications))
+
+	for _, application := range server.applications {
+		server.expireIfDue(application)
+		output = append(output, *application)
+	}
+
+	sort.Slice(output, func(i, j int) bool {
+		return output[i].Token < output[j].Token
+	})
+
+	return output
+}
+
+// Must be called with the mutex held.
+func (server *Server) now() time.Time {
+	return time.Now().Add(server.timeOffset)
+}
+
+// Moves an application to "expired" if its expiry time has passed, and sends a webhook
+// for the change. Must be called with the mutex held.
+func (server *Server) expireIfDue(application *Application) {
+	if !application.Status.IsTerminal() && !server.now().Before(application.ExpiresAt) {
+		application.Status = pasdk.StatusExpired
+		server.queueWebhook(buildWebhookEvent(*application))
+	}
+}
+
+func defaultAccount() pasdk.AccountResponse {
+	maxAmount := pasdk.Money(500000)
+	minAmount := pasdk.Money(10000)
+	fixedFee := pasdk.Money(5000)
+
+	return pasdk.AccountResponse{
+		LegalName:   "Test Merchant Ltd",
+		DisplayName: "Test Merchant",
+		Plans: []pasdk.Plan{
+			{
+				ID:              1,
+				Name:            "4-Payment",
+				Instalments:     4,
+				DepositRequired: true,
+				APR:             "0",
+				Frequency:       "monthly",
+				MaxAmount:       &maxAmount,
+				CommissionRate:  "8.50",
+			},
+			{
+				ID:                 2,
+				Name:               "12-Payment",
+				Instalments:        12,
+				DepositRequired:    false,
+				APR:                "9.9",
+				Frequency:          "monthly",
+				MinAmount:          &minAmount,
+				MaxAmount:          &maxAmount,
+				CommissionRate:     "0",
+				CommissionFixedFee: &fixedFee,
+			},
+		},
+	}
+}
+
+// Wraps a handler so that it only accepts the given method and correctly signed requests.
+func (server *Server) requireMethod(method string, handler func(http.ResponseWriter, url.Values)) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != method {
+			writeError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		if err := request.ParseForm(); err != nil {
+			writeError(writer, http.StatusBadRequest, "Malformed request")
+			return
+		}
+
+		params := request.Form
+
+		if params.Get("api_key") != APIKey {
+			writeError(writer, http.StatusUnauthorized, "Invalid API key")
+			return
+		}
+
+		if !hmac.Equal([]byte(params.Get("signature")), []byte(sign(params))) {
+			writeError(writer, http.StatusUnauthorized, "Invalid signature")
+			return
+		}
+
+		handler(writer, params)
+	}
+}
+
+// Returns the signature Payment Assist expects for the given parameters: an HMAC-SHA256
+// of every parameter other than the API key and signature, sorted by name, with the names
+// in upper case, formatted as "NAME=value&".
+func sign(params url.Values) string {
+	keys := make([]string, 0, len(params))
+
+	for key := range params {
+		if key != "api_key" && key != "signature" {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	var signed strings.Builder
+
+	for _, key := range keys {
+		signed.WriteString(strings.ToUpper(key) + "=" + params.Get(key) + "&")
+	}
+
+	hasher := hmac.New(sha256.New, []byte(APISecret))
+	hasher.Write([]byte(signed.String()))
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+func writeData(writer http.ResponseWriter, data interface{}) {
+	writeJSON(writer, http.StatusOK, map[string]interface{}{
+		"status": "ok",
+		"msg":    nil,
+		"data":   data,
+	})
+}
+
+func writeError(writer http.ResponseWriter, statusCode int, message string) {
+	writeJSON(writer, statusCode, map[string]interface{}{
+		"status": "error",
+		"msg":    message,
+		"data":   []interface{}{},
+	})
+}
+
+func writeJSON(writer http.ResponseWriter, statusCode int, body interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	json.NewEncoder(writer).Encode(body)
+}
+
+// Returns a random version 4 UUID.
+func newToken() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+
+	bytes[6] = (bytes[6] & 0x0f) | 0x40
+	bytes[8] = (bytes[8] & 0x3f) | 0x80
+
+	encoded := hex.EncodeToString(bytes)
+
+	return encoded[0:8] + "-" + encoded[8:12] + "-" + encoded[12:16] + "-" + encoded[16:20] + "-" + encoded[20:32]
+}
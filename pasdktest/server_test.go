@@ -0,0 +1,465 @@
+package pasdktest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+	"github.com/paymentassist/paymentassist-go/webhook"
+)
+
+func beginTestApplication(t *testing.T, client *pasdk.Client, autoCapture bool) string {
+	response, err := client.Begin(pasdk.BeginRequest{
+		OrderID:           "order-" + newToken()[:8],
+		Amount:            50000,
+		CustomerFirstName: "Test",
+		CustomerLastName:  "Testington",
+		CustomerAddress1:  "Test House",
+		CustomerPostcode:  "TE1 1ST",
+		EnableAutoCapture: &autoCapture,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.ApplicationToken) != 36 {
+		t.Error(response.ApplicationToken)
+	}
+
+	return response.ApplicationToken
+}
+
+func Test_Server_ApplicationLifecycle(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	token := beginTestApplication(t, client, false)
+
+	status, err := client.Status(pasdk.StatusRequest{ApplicationToken: token})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != pasdk.StatusPending || status.Amount != 50000 {
+		t.Error(status)
+	}
+
+	amount := pasdk.Money(40000)
+
+	_, err = client.Update(pasdk.UpdateRequest{ApplicationToken: token, Amount: &amount})
+
+	if err != nil {
+		t.Error(err)
+	}
+
+	_, err = client.Capture(pasdk.CaptureRequest{ApplicationToken: token})
+
+	if err == nil || err.APIMessage != "Application is not awaiting capture" {
+		t.Error(err)
+	}
+
+	if err := server.Approve(token); err != nil {
+		t.Fatal(err)
+	}
+
+	status, _ = client.Status(pasdk.StatusRequest{ApplicationToken: token})
+
+	if status.Status != pasdk.StatusPendingCapture || status.Amount != 40000 {
+		t.Error(status)
+	}
+
+	capture, err := client.Capture(pasdk.CaptureRequest{ApplicationToken: token})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capture.Status != pasdk.StatusCompleted || capture.DepositCaptured == nil || !*capture.DepositCaptured {
+		t.Error(capture)
+	}
+
+	invoice, err := client.Invoice(pasdk.InvoiceRequest{
+		ApplicationToken: token,
+		FileType:         "txt",
+		FileData:         []byte("Test invoice."), // Encodes with "=" padding.
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invoice.UploadStatus != "success" {
+		t.Error(invoice.UploadStatus)
+	}
+
+	application, _ := server.Application(token)
+
+	if !application.HasInvoice || len(application.PaymentAssistReference) == 0 {
+		t.Error(application)
+	}
+}
+
+func Test_Server_ExpiresApplications(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	token := beginTestApplication(t, client, true)
+
+	server.AdvanceTime(25 * time.Hour)
+
+	status, err := client.Status(pasdk.StatusRequest{ApplicationToken: token})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != pasdk.StatusExpired {
+		t.Error(status.Status)
+	}
+
+	if server.Approve(token) == nil {
+		t.Error()
+	}
+}
+
+func Test_Server_SendsWebhooksWhenApplicationsExpire(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	received := make(chan webhook.Event, 10)
+
+	handler := webhook.NewHandler()
+	handler.OnExpired(func(ctx context.Context, event webhook.ExpiredEvent) error {
+		received <- event.Event
+		return nil
+	})
+
+	webhookServer := httptest.NewServer(handler)
+	defer webhookServer.Close()
+
+	client := server.Client()
+	webhookURL := webhookServer.URL
+
+	begin := func(orderID string) string {
+		response, err := client.Begin(pasdk.BeginRequest{
+			OrderID:           orderID,
+			Amount:            50000,
+			CustomerFirstName: "Test",
+			CustomerLastName:  "Testington",
+			CustomerAddress1:  "Test House",
+			CustomerPostcode:  "TE1 1ST",
+			WebhookURL:        &webhookURL,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return response.ApplicationToken
+	}
+
+	// Setting the expiry to now expires the application straight away.
+	updatedToken := begin("expiry-order")
+	expiresIn := 0
+
+	if _, err := client.Update(pasdk.UpdateRequest{ApplicationToken: updatedToken, ExpiresIn: &expiresIn}); err != nil {
+		t.Fatal(err)
+	}
+
+	server.WaitForWebhooks()
+
+	// Other applications expire once their expiry time is seen to have passed.
+	advancedToken := begin("advanced-order")
+	server.AdvanceTime(25 * time.Hour)
+
+	if _, err := client.Status(pasdk.StatusRequest{ApplicationToken: advancedToken}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The application has already expired, so fetching it again doesn't send another webhook.
+	if _, err := client.Status(pasdk.StatusRequest{ApplicationToken: advancedToken}); err != nil {
+		t.Fatal(err)
+	}
+
+	server.WaitForWebhooks()
+	close(received)
+
+	var tokens []string
+
+	for event := range received {
+		tokens = append(tokens, event.ApplicationToken)
+	}
+
+	if len(tokens) != 2 || tokens[0] != updatedToken || tokens[1] != advancedToken {
+		t.Error(tokens)
+	}
+}
+
+func Test_Server_RejectsBadSignatures(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	credentials := server.Credentials()
+	credentials.APISecret = "wrong"
+
+	client := pasdk.NewClient(credentials, pasdk.WithHTTPClient(server.HTTPClient()))
+
+	_, err := client.Account(pasdk.AccountRequest{})
+
+	if err == nil || err.StatusCode != http.StatusUnauthorized || err.APIMessage != "Invalid signature" {
+		t.Error(err)
+	}
+
+	credentials = server.Credentials()
+	credentials.APIKey = "wrong"
+
+	client = pasdk.NewClient(credentials, pasdk.WithHTTPClient(server.HTTPClient()))
+
+	_, err = client.Account(pasdk.AccountRequest{})
+
+	if err == nil || err.APIMessage != "Invalid API key" {
+		t.Error(err)
+	}
+}
+
+func Test_Server_AccountPlanAndPreapproval(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.Client()
+
+	account, err := client.Account(pasdk.AccountRequest{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(account.Plans) != 2 || account.Plans[1].APR != "9.9" {
+		t.Error(account)
+	}
+
+	plan, err := client.Plan(pasdk.PlanRequest{Amount: 50000, PlanID: &account.Plans[0].ID})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.PaymentSchedule) != 4 || plan.TotalRepayable != 50000 {
+		t.Error(plan)
+	}
+
+	comparison, err := client.VerifySchedule(context.Background(), account.Plans[0], 50000)
+
+	if err != nil || !comparison.Matches() {
+		t.Error(err, comparison)
+	}
+
+	server.SetPreapproval(false)
+
+	preapproval, err := client.Preapproval(pasdk.PreapprovalRequest{
+		CustomerFirstName: "Test",
+		CustomerLastName:  "Testington",
+		CustomerAddress1:  "Test House",
+		CustomerPostcode:  "TE1 1ST",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preapproval.Approved {
+		t.Error()
+	}
+}
+
+func Test_Server_AdminAPIAndWebhooks(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	received := make(chan pasdk.ApplicationStatus, 10)
+
+	handler := webhook.NewHandler()
+	handler.OnStatusChanged(func(ctx context.Context, event webhook.StatusChangedEvent) error {
+		received <- event.Status
+		return nil
+	})
+
+	webhookServer := httptest.NewServer(handler)
+	defer webhookServer.Close()
+
+	client := server.Client()
+	webhookURL := webhookServer.URL
+
+	response, err := client.Begin(pasdk.BeginRequest{
+		OrderID:           "webhook-order",
+		Amount:            50000,
+		CustomerFirstName: "Test",
+		CustomerLastName:  "Testington",
+		CustomerAddress1:  "Test House",
+		CustomerPostcode:  "TE1 1ST",
+		WebhookURL:        &webhookURL,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adminResponse, postErr := server.HTTPClient().Post(server.URL+"admin/decline/"+response.ApplicationToken, "", nil)
+
+	if postErr != nil {
+		t.Fatal(postErr)
+	}
+
+	adminResponse.Body.Close()
+
+	if adminResponse.StatusCode != http.StatusOK {
+		t.Error(adminResponse.StatusCode)
+	}
+
+	select {
+	case status := <-received:
+		if status != pasdk.StatusDeclined {
+			t.Error(status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("webhook was not received")
+	}
+
+	application, _ := server.Application(response.ApplicationToken)
+
+	if application.Status != pasdk.StatusDeclined {
+		t.Error(application.Status)
+	}
+}
+
+func Test_Server_CaptureDoesNotWaitForWebhooks(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	release := make(chan struct{})
+	var statuses []pasdk.ApplicationStatus
+
+	// The receiver calls back into the server, and doesn't respond until it is released.
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		event, err := webhook.Parse(request)
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if _, paErr := client.Status(pasdk.StatusRequest{ApplicationToken: event.ApplicationToken}); paErr != nil {
+			t.Error(paErr)
+		}
+
+		<-release
+		statuses = append(statuses, event.Status)
+	}))
+	defer webhookServer.Close()
+
+	webhookURL := webhookServer.URL
+	autoCapture := false
+
+	response, err := client.Begin(pasdk.BeginRequest{
+		OrderID:           "slow-webhook-order",
+		Amount:            50000,
+		CustomerFirstName: "Test",
+		CustomerLastName:  "Testington",
+		CustomerAddress1:  "Test House",
+		CustomerPostcode:  "TE1 1ST",
+		EnableAutoCapture: &autoCapture,
+		WebhookURL:        &webhookURL,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := server.Approve(response.ApplicationToken); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.Capture(pasdk.CaptureRequest{ApplicationToken: response.ApplicationToken})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	close(release)
+	server.WaitForWebhooks()
+
+	if len(statuses) != 2 || statuses[0] != pasdk.StatusPendingCapture || statuses[1] != pasdk.StatusCompleted {
+		t.Error(statuses)
+	}
+}
+
+func Test_Server_PlanSchedules(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	interestFree, interestBearing := 1, 2
+
+	plan, err := client.Plan(pasdk.PlanRequest{Amount: 50003, PlanID: &interestFree})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	expectedAmounts := []pasdk.Money{12501, 12501, 12501, 12500}
+
+	if len(plan.PaymentSchedule) != 4 || plan.Interest != 0 || plan.TotalRepayable != 50003 {
+		t.Fatal(plan)
+	}
+	if plan.PaymentSchedule[0].Date.Format("2006-01-02") != today {
+		t.Error(plan.PaymentSchedule[0].Date)
+	}
+
+	for i, repayment := range plan.PaymentSchedule {
+		if repayment.Amount != expectedAmounts[i] {
+			t.Error(i, repayment.Amount)
+		}
+	}
+
+	_, err = client.Plan(pasdk.PlanRequest{Amount: 50000, PlanID: &interestBearing})
+
+	if err == nil || !err.IsRequestRefusedError {
+		t.Error(err)
+	}
+
+	fixture := pasdk.PlanResponse{
+		PlanName:       "12-Payment",
+		Amount:         50000,
+		Interest:       2264,
+		TotalRepayable: 52264,
+	}
+
+	for month := 1; month <= 12; month++ {
+		amount := pasdk.Money(4355)
+
+		if month <= 4 {
+			amount++
+		}
+
+		fixture.PaymentSchedule = append(fixture.PaymentSchedule, pasdk.Repayment{
+			Date:   time.Date(2024, time.Month(month), 15, 0, 0, 0, 0, time.UTC),
+			Amount: amount,
+		})
+	}
+
+	server.SetPlanSchedule(interestBearing, 50000, fixture)
+
+	plan, err = client.Plan(pasdk.PlanRequest{Amount: 50000, PlanID: &interestBearing})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Interest != 2264 || plan.TotalRepayable != 52264 || len(plan.PaymentSchedule) != 12 {
+		t.Error(plan)
+	}
+	if plan.PaymentSchedule[0].Amount != 4356 || plan.PaymentSchedule[11].Date.Format("2006-01-02") != "2024-12-15" {
+		t.Error(plan.PaymentSchedule)
+	}
+}
@@ -1,23 +1,49 @@
 package pasdk
 
+import "context"
+
 // PlanRequest accepts a transaction amount and an optional plan ID,
 // returning a full payment schedule including amounts and dates.
 type PlanRequest struct {
-	Amount int  // The invoice amount in pence.
-	PlanID *int // The plan ID. If empty, the account's default plan is used.
+	Amount Money // The invoice amount in pence.
+	PlanID *int  // The plan ID. If empty, the account's default plan is used.
 }
 
 // PlanResponse contains the data returned by a successful call to the "plan" endpoint.
 type PlanResponse struct {
 	PlanName        string      `json:"plan"`      // The name of this plan.
-	Amount          int         `json:"amount"`    // The amount you requested, in pence.
-	Interest        int         `json:"interest"`  // The amount of interest payable, in pence.
-	TotalRepayable  int         `json:"repayable"` // The total amount that would be repayable under this plan, in pence.
+	Amount          Money       `json:"amount"`    // The amount you requested, in pence.
+	Interest        Money       `json:"interest"`  // The amount of interest payable, in pence.
+	TotalRepayable  Money       `json:"repayable"` // The total amount that would be repayable under this plan, in pence.
 	PaymentSchedule []Repayment `json:"schedule"`  // A breakdown of what the repayments would look like under this plan.
 }
 
-// Fetch executes the request.
-func (request PlanRequest) Fetch() (response *PlanResponse, err *PASDKError) {
+// Fetch executes the request using the credentials passed to Initialise.
+func (request PlanRequest) Fetch() (*PlanResponse, *PASDKError) {
+	return defaultClient.PlanContext(context.Background(), request)
+}
+
+// FetchContext executes the request using the credentials passed to Initialise. The
+// request is abandoned as soon as ctx is cancelled or its deadline passes.
+func (request PlanRequest) FetchContext(ctx context.Context) (*PlanResponse, *PASDKError) {
+	return defaultClient.PlanContext(ctx, request)
+}
+
+// Validate checks the request without sending it, and returns a validation error listing
+// every problem found in FieldErrors, or nil if the request is valid.
+func (request PlanRequest) Validate() *PASDKError {
+	return validatePlanRequest(request)
+}
+
+// Plan executes the given request using this client's credentials.
+func (client *Client) Plan(request PlanRequest) (*PlanResponse, *PASDKError) {
+	return client.PlanContext(context.Background(), request)
+}
+
+// PlanContext is like Plan, but the request is abandoned as soon as ctx is
+// cancelled or its deadline passes.
+func (client *Client) PlanContext(ctx context.Context, request PlanRequest) (response *PlanResponse, err *PASDKError) {
+	defer client.logFailure(ctx, "plan", &err)
 	defer catchGenericPanic(&response, &err)
 
 	err = validatePlanRequest(request)
@@ -26,26 +52,34 @@ func (request PlanRequest) Fetch() (response *PlanResponse, err *PASDKError) {
 		return nil, err.Wrap("request is invalid: ")
 	}
 
+	if client.planPreflight && request.PlanID != nil {
+		err = client.checkAccountPlans(ctx, request.CheckAgainstAccount)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Alphabetically sorted.
-	requestParams := []string{
-		"amount=" + toString(request.Amount),
-		"plan_id=" + toString(request.PlanID),
+	requestParams := []requestParam{
+		{"amount", toString(request.Amount)},
+		{"plan_id", toString(request.PlanID)},
 	}
 
 	requestParams = removeEmptyParams(requestParams)
 
-	signature := generateSignature(requestParams, userCredentials.APISecret)
+	signature := generateSignature(requestParams, client.credentials.APISecret)
 
-	requestParams = append(requestParams, "api_key="+userCredentials.APIKey)
-	requestParams = append(requestParams, "signature="+signature)
+	requestParams = append(requestParams, requestParam{"api_key", client.credentials.APIKey})
+	requestParams = append(requestParams, requestParam{"signature", signature})
 
-	requestURL, err := getRequestURL()
+	requestURL, err := client.getRequestURL()
 
 	if err != nil {
 		return nil, err.Wrap("failed determining request URL: ")
 	}
 
-	response, err = makeAPIPOSTRequest[PlanResponse](requestParams, requestURL+"plan")
+	response, err = makeAPIPOSTRequest[PlanResponse](ctx, client, requestParams, requestURL+"plan")
 
 	if err != nil {
 		return nil, err.Wrap("API request failed: ")
@@ -55,9 +89,11 @@ func (request PlanRequest) Fetch() (response *PlanResponse, err *PASDKError) {
 }
 
 func validatePlanRequest(request PlanRequest) (err *PASDKError) {
+	validation := validation{}
+
 	if request.Amount <= 0 {
-		return buildValidationFailedError("field Amount must be greater than 0")
+		validation.fail("Amount", FieldErrorNotPositive, "field Amount must be greater than 0")
 	}
 
-	return nil
+	return validation.err()
 }
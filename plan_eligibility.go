@@ -0,0 +1,148 @@
+package pasdk
+
+import (
+	"context"
+	"strconv"
+)
+
+// PlanExclusionReason identifies why a plan can't be used for an amount.
+type PlanExclusionReason string
+
+// The reasons a plan can't be used for an amount.
+const (
+	PlanAmountBelowMinimum PlanExclusionReason = "amount_below_minimum" // The amount is less than the plan's MinAmount.
+	PlanAmountAboveMaximum PlanExclusionReason = "amount_above_maximum" // The amount is more than the plan's MaxAmount.
+)
+
+// ExcludedPlan is a plan that can't be used for an amount, along with the reason why.
+type ExcludedPlan struct {
+	Plan    Plan                // The plan that was excluded.
+	Reason  PlanExclusionReason // Why the plan was excluded, such as PlanAmountBelowMinimum.
+	Message string              // A description of why the plan was excluded.
+}
+
+// PlanEligibility lists which of an account's plans can be used for an amount.
+type PlanEligibility struct {
+	Eligible []Plan         // The plans that can be used, in the order the account lists them.
+	Excluded []ExcludedPlan // The plans that can't be used, in the order the account lists them.
+}
+
+// EligiblePlans works out which of the account's plans can be used for the given amount,
+// based on each plan's MinAmount and MaxAmount. The limits are inclusive, and a plan
+// without a limit has no minimum or maximum.
+func (account AccountResponse) EligiblePlans(amount Money) PlanEligibility {
+	eligibility := PlanEligibility{}
+
+	for _, plan := range account.Plans {
+		if excluded := checkPlanAmount(plan, amount); excluded != nil {
+			eligibility.Excluded = append(eligibility.Excluded, *excluded)
+		} else {
+			eligibility.Eligible = append(eligibility.Eligible, plan)
+		}
+	}
+
+	return eligibility
+}
+
+// FindPlan returns the account's plan with the given ID, or false if there isn't one.
+func (account AccountResponse) FindPlan(planID int) (Plan, bool) {
+	for _, plan := range account.Plans {
+		if plan.ID == planID {
+			return plan, true
+		}
+	}
+
+	return Plan{}, false
+}
+
+// Returns why the plan can't be used for the amount, or nil if it can.
+func checkPlanAmount(plan Plan, amount Money) *ExcludedPlan {
+	if plan.MinAmount != nil && amount < *plan.MinAmount {
+		return &ExcludedPlan{plan, PlanAmountBelowMinimum, "the amount of " + amount.String() +
+			" is less than the minimum of " + plan.MinAmount.String() + " for plan \"" + plan.Name + "\""}
+	}
+
+	if plan.MaxAmount != nil && amount > *plan.MaxAmount {
+		return &ExcludedPlan{plan, PlanAmountAboveMaximum, "the amount of " + amount.String() +
+			" is more than the maximum of " + plan.MaxAmount.String() + " for plan \"" + plan.Name + "\""}
+	}
+
+	return nil
+}
+
+// Returns the problem with planID if it isn't one of the account's plans or can't be used
+// for the amount. Nothing is checked if planID is nil, as the account's default plan is used.
+func checkPlanID(account AccountResponse, planID *int, amount Money) *FieldError {
+	if planID == nil {
+		return nil
+	}
+
+	plan, exists := account.FindPlan(*planID)
+
+	if !exists {
+		return &FieldError{"PlanID", FieldErrorUnknownPlan,
+			"PlanID " + strconv.Itoa(*planID) + " is not one of the account's plans"}
+	}
+
+	if excluded := checkPlanAmount(plan, amount); excluded != nil {
+		return &FieldError{"Amount", FieldErrorAmountOutsidePlanLimits, excluded.Message}
+	}
+
+	return nil
+}
+
+// CheckAgainstAccount returns a validation error if PlanID is set but isn't one of the
+// account's plans, or the plan can't be used for Amount.
+func (request BeginRequest) CheckAgainstAccount(account AccountResponse) *PASDKError {
+	validation := validation{}
+	validation.check(checkPlanID(account, request.PlanID, request.Amount))
+
+	return validation.err()
+}
+
+// CheckAgainstAccount returns a validation error if PlanID is set but isn't one of the
+// account's plans, or the plan can't be used for Amount.
+func (request PlanRequest) CheckAgainstAccount(account AccountResponse) *PASDKError {
+	validation := validation{}
+	validation.check(checkPlanID(account, request.PlanID, request.Amount))
+
+	return validation.err()
+}
+
+// WithPlanPreflight makes the client check the account's plans before sending a
+// BeginRequest or PlanRequest with a PlanID, and fail with a validation error instead
+// of sending the request if the plan isn't on the account or can't be used for the
+// amount. The account is taken from the cache set with WithAccountCache, or if there
+// isn't one, from a cache the client creates with the default AccountCacheOptions, so
+// the "account" endpoint is only called when the cached account has expired.
+func WithPlanPreflight() ClientOption {
+	return func(client *Client) {
+		client.planPreflight = true
+	}
+}
+
+// WithAccountCache makes the client use the given cache whenever it needs the account,
+// such as for WithPlanPreflight. The cache can be shared with the rest of your code.
+func WithAccountCache(cache *AccountCache) ClientOption {
+	return func(client *Client) {
+		client.accountCache = cache
+	}
+}
+
+// Gets the account from the client's cache and passes it to check, returning an error if
+// the account couldn't be fetched or check rejected it.
+func (client *Client) checkAccountPlans(ctx context.Context, check func(AccountResponse) *PASDKError) *PASDKError {
+	account, err := client.accountCache.Get(ctx)
+
+	if err != nil {
+		return err.Wrap("failed checking the account's plans: ")
+	}
+
+	err = check(*account)
+
+	if err != nil {
+		return err.Wrap("request is invalid for the account's plans: ")
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package pasdk
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newTestAccount() AccountResponse {
+	min := Pounds(100)
+	max := Pounds(3000)
+	fixedMax := Pounds(5000)
+
+	return AccountResponse{
+		Plans: []Plan{
+			{ID: 6, Name: "3-Payment", MaxAmount: &fixedMax},
+			{ID: 1, Name: "4-Payment", MinAmount: &min, MaxAmount: &max},
+			{ID: 9, Name: "Unlimited"},
+		},
+	}
+}
+
+func Test_AccountResponse_EligiblePlans(t *testing.T) {
+	account := newTestAccount()
+
+	eligibility := account.EligiblePlans(Pounds(50))
+
+	if len(eligibility.Eligible) != 2 || eligibility.Eligible[0].ID != 6 || eligibility.Eligible[1].ID != 9 {
+		t.Error(eligibility.Eligible)
+	}
+
+	if len(eligibility.Excluded) != 1 || eligibility.Excluded[0].Plan.ID != 1 ||
+		eligibility.Excluded[0].Reason != PlanAmountBelowMinimum ||
+		eligibility.Excluded[0].Message != "the amount of £50.00 is less than the minimum of £100.00 for plan \"4-Payment\"" {
+		t.Error(eligibility.Excluded)
+	}
+
+	eligibility = account.EligiblePlans(Pounds(4000))
+
+	if len(eligibility.Eligible) != 2 || len(eligibility.Excluded) != 1 ||
+		eligibility.Excluded[0].Reason != PlanAmountAboveMaximum {
+		t.Error(eligibility)
+	}
+
+	// The limits are inclusive.
+	eligibility = account.EligiblePlans(Pounds(3000))
+
+	if len(eligibility.Eligible) != 3 || len(eligibility.Excluded) != 0 {
+		t.Error(eligibility)
+	}
+}
+
+func Test_CheckAgainstAccount(t *testing.T) {
+	account := newTestAccount()
+	planID := 1
+
+	if err := (PlanRequest{Amount: Pounds(500), PlanID: &planID}).CheckAgainstAccount(account); err != nil {
+		t.Error(err)
+	}
+
+	if err := (PlanRequest{Amount: Pounds(50)}).CheckAgainstAccount(account); err != nil {
+		t.Error(err)
+	}
+
+	err := BeginRequest{Amount: Pounds(50), PlanID: &planID}.CheckAgainstAccount(account)
+
+	if err == nil || !err.IsValidationFailedError || len(err.FieldErrors) != 1 ||
+		err.FieldErrors[0].Field != "Amount" || err.FieldErrors[0].Code != FieldErrorAmountOutsidePlanLimits {
+		t.Error(err)
+	}
+
+	planID = 42
+	err = BeginRequest{Amount: Pounds(50), PlanID: &planID}.CheckAgainstAccount(account)
+
+	if err == nil || err.FieldErrors[0].Code != FieldErrorUnknownPlan ||
+		err.Error() != "PlanID 42 is not one of the account's plans" {
+		t.Error(err)
+	}
+}
+
+func Test_WithPlanPreflight(t *testing.T) {
+	var endpoints []string
+
+	_, client := newTestServerClient(t, PAAuth{APIKey: "key", APISecret: "secret"},
+		func(writer http.ResponseWriter, request *http.Request) {
+			endpoints = append(endpoints, getEndpointName(request.URL.Path))
+
+			if strings.HasSuffix(request.URL.Path, "account") {
+				writer.Write([]byte(`{"status":"ok","msg":null,"data":{"plans":[` +
+					`{"plan_id":1,"name":"4-Payment","min_amount":10000,"max_amount":300000}]}}`))
+				return
+			}
+
+			writer.Write([]byte(`{"status":"ok","msg":null,"data":{"plan":"4-Payment","amount":50000}}`))
+		})
+
+	client = NewClient(client.credentials, WithHTTPClient(client.httpClient), WithPlanPreflight())
+	planID := 1
+
+	if _, err := client.Plan(PlanRequest{Amount: Pounds(500), PlanID: &planID}); err != nil {
+		t.Error(err)
+	}
+
+	_, err := client.Plan(PlanRequest{Amount: Pounds(5000), PlanID: &planID})
+
+	if err == nil || !err.IsValidationFailedError ||
+		!strings.HasPrefix(err.Error(), "request is invalid for the account's plans: ") {
+		t.Error(err)
+	}
+
+	// Requests without a PlanID use the default plan, so the account isn't checked.
+	if _, err := client.Plan(PlanRequest{Amount: Pounds(500)}); err != nil {
+		t.Error(err)
+	}
+
+	// The account is cached, so it is only fetched once.
+	if strings.Join(endpoints, ",") != "account,plan,plan" {
+		t.Error(endpoints)
+	}
+
+	endpoints = nil
+	cache := client.NewAccountCache(AccountCacheOptions{})
+
+	if _, err := cache.Get(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	client = NewClient(client.credentials, WithHTTPClient(client.httpClient), WithPlanPreflight(), WithAccountCache(cache))
+
+	if _, err := client.Plan(PlanRequest{Amount: Pounds(500), PlanID: &planID}); err != nil {
+		t.Error(err)
+	}
+
+	if strings.Join(endpoints, ",") != "account,plan" {
+		t.Error(endpoints)
+	}
+}
@@ -49,9 +49,9 @@ func Test_Plan(t *testing.T) {
 func Test_validatePlanRequest(t *testing.T) {
 	request := PlanRequest{}
 
-	if validatePlanRequest(request).Error() != "field Amount must be greater than 0" {
-		t.Error()
-	}
+	checkFieldErrors(t, validatePlanRequest(request), []FieldError{
+		{"Amount", FieldErrorNotPositive, "field Amount must be greater than 0"},
+	})
 
 	request.Amount = 10000
 
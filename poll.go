@@ -0,0 +1,198 @@
+package pasdk
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// PollOptions controls how often WaitForStatus and WatchStatus check an application's
+// status. The interval between checks starts at InitialInterval and grows by Multiplier
+// after every check that finds no change, up to MaxInterval. It goes back to
+// InitialInterval whenever the status changes.
+type PollOptions struct {
+	InitialInterval time.Duration // How long to wait before the second check. Defaults to 2 seconds.
+	MaxInterval     time.Duration // The longest time to wait between two checks. Defaults to 30 seconds.
+	Multiplier      float64       // How much the interval grows after each check. Values below 1 are treated as 1.
+}
+
+// DefaultPollOptions returns the poll options used unless WithPollOptions is passed to NewClient.
+func DefaultPollOptions() PollOptions {
+	return PollOptions{
+		InitialInterval: 2 * time.Second,
+		MaxInterval:     30 * time.Second,
+		Multiplier:      1.5,
+	}
+}
+
+// WithPollOptions sets how often WaitForStatus and WatchStatus check an application's status.
+func WithPollOptions(options PollOptions) ClientOption {
+	return func(client *Client) {
+		client.pollOptions = options
+	}
+}
+
+// StatusChange describes a change in an application's status seen by WatchStatus.
+type StatusChange struct {
+	PreviousStatus ApplicationStatus // The status before the change. This is empty for the first status seen.
+	Response       *StatusResponse   // The response in which the new status was seen. This is nil if Err is set.
+	Err            *PASDKError       // The error that stopped the watch, if any. This is always the last value sent.
+}
+
+// WaitForStatus polls the status of an application using the credentials passed to Initialise,
+// until it reaches one of the given statuses. See Client.WaitForStatus for details.
+func WaitForStatus(ctx context.Context, applicationToken string, targetStatuses ...ApplicationStatus) (*StatusResponse, *PASDKError) {
+	return defaultClient.WaitForStatus(ctx, applicationToken, targetStatuses...)
+}
+
+// WaitForStatus polls the status of an application until it reaches one of the given
+// statuses, and returns the response in which that status was seen. If no statuses are
+// given, it waits until the application is completed, declined or expired.
+//
+// Polling also stops once the application can no longer change, because it has reached one
+// of those final statuses or its expiry time has passed. In that case the last response is
+// returned without an error, so check its Status to see whether the target was reached.
+// An error is returned if ctx is cancelled or a status request fails.
+func (client *Client) WaitForStatus(ctx context.Context, applicationToken string,
+	targetStatuses ...ApplicationStatus) (*StatusResponse, *PASDKError) {
+	watchContext, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var lastResponse *StatusResponse
+
+	for change := range client.WatchStatus(watchContext, applicationToken) {
+		if change.Err != nil {
+			return nil, change.Err
+		}
+
+		lastResponse = change.Response
+
+		if len(targetStatuses) == 0 && lastResponse.Status.IsTerminal() {
+			return lastResponse, nil
+		}
+
+		for _, status := range targetStatuses {
+			if lastResponse.Status == status {
+				return lastResponse, nil
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil, buildCancelledError("waiting for application status was cancelled: " + ctx.Err().Error()).
+			withCause(ctx.Err())
+	}
+
+	return lastResponse, nil
+}
+
+// WatchStatus polls the status of an application using the credentials passed to Initialise.
+// See Client.WatchStatus for details.
+func WatchStatus(ctx context.Context, applicationToken string) <-chan StatusChange {
+	return defaultClient.WatchStatus(ctx, applicationToken)
+}
+
+// WatchStatus polls the status of an application in the background and sends a value on
+// the returned channel every time its status changes, starting with its current status.
+// The channel is closed once the application is completed, declined or expired, its
+// expiry time has passed, a status request fails or ctx is cancelled. If a request fails,
+// the error is sent before the channel is closed.
+func (client *Client) WatchStatus(ctx context.Context, applicationToken string) <-chan StatusChange {
+	changes := make(chan StatusChange)
+
+	go func() {
+		defer close(changes)
+
+		previousStatus := ApplicationStatus("")
+		interval := client.pollOptions.initialInterval()
+
+		for {
+			response, err := client.StatusContext(ctx, StatusRequest{ApplicationToken: applicationToken})
+
+			if err != nil {
+				if ctx.Err() == nil {
+					sendStatusChange(ctx, changes, StatusChange{PreviousStatus: previousStatus, Err: err})
+				}
+
+				return
+			}
+
+			if response.Status != previousStatus {
+				if !sendStatusChange(ctx, changes, StatusChange{PreviousStatus: previousStatus, Response: response}) {
+					return
+				}
+
+				previousStatus = response.Status
+				interval = client.pollOptions.initialInterval()
+			} else {
+				interval = client.pollOptions.nextInterval(interval)
+			}
+
+			if response.Status.IsTerminal() {
+				return
+			}
+
+			wait := interval
+
+			if !response.ExpiresAt.IsZero() {
+				untilExpiry := time.Until(response.ExpiresAt)
+
+				// Once the application has expired its status won't change again.
+				if untilExpiry <= 0 {
+					return
+				}
+
+				// Check again as soon as it expires rather than waiting a full interval.
+				if untilExpiry < wait {
+					wait = untilExpiry
+				}
+			}
+
+			timer := time.NewTimer(wait)
+
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+
+	return changes
+}
+
+// Sends change on changes unless ctx is cancelled first. Returns false if it wasn't sent.
+func sendStatusChange(ctx context.Context, changes chan<- StatusChange, change StatusChange) bool {
+	select {
+	case changes <- change:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (options PollOptions) initialInterval() time.Duration {
+	if options.InitialInterval <= 0 {
+		return DefaultPollOptions().InitialInterval
+	}
+
+	return options.InitialInterval
+}
+
+// Returns the interval to wait after the given one.
+func (options PollOptions) nextInterval(interval time.Duration) time.Duration {
+	next := time.Duration(float64(interval) * math.Max(options.Multiplier, 1))
+
+	maxInterval := options.MaxInterval
+
+	if maxInterval <= 0 {
+		maxInterval = DefaultPollOptions().MaxInterval
+	}
+
+	if next > maxInterval {
+		return maxInterval
+	}
+
+	return next
+}
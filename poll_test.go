@@ -0,0 +1,150 @@
+package pasdk
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Returns a client whose status requests return each of the given statuses in turn,
+// repeating the last one once they run out.
+func newStatusSequenceClient(expiresAt time.Time, statuses ...string) *Client {
+	var mutex sync.Mutex
+	calls := 0
+
+	transport := func(request *http.Request) (*http.Response, error) {
+		mutex.Lock()
+		status := statuses[len(statuses)-1]
+
+		if calls < len(statuses) {
+			status = statuses[calls]
+		}
+
+		calls++
+		mutex.Unlock()
+
+		return newStaticTransport(200, `{"status":"ok","msg":null,"data":{"token":"test","status":"`+status+
+			`","expires_at":"`+expiresAt.Format(time.RFC3339)+`"}}`)(request)
+	}
+
+	return NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithTransport(RoundTripperFunc(transport)),
+		WithPollOptions(PollOptions{
+			InitialInterval: time.Millisecond,
+			MaxInterval:     5 * time.Millisecond,
+			Multiplier:      2,
+		}))
+}
+
+func Test_WaitForStatus_ReturnsWhenTargetReached(t *testing.T) {
+	client := newStatusSequenceClient(time.Now().Add(time.Hour), "pending", "pending", "in_progress", "pending_capture")
+
+	response, err := client.WaitForStatus(context.Background(), "test", "pending_capture", "completed")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if response.Status != "pending_capture" {
+		t.Error(response.Status)
+	}
+}
+
+func Test_WaitForStatus_StopsAtFinalStatus(t *testing.T) {
+	client := newStatusSequenceClient(time.Now().Add(time.Hour), "pending", "declined")
+
+	response, err := client.WaitForStatus(context.Background(), "test", "completed")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if response.Status != "declined" {
+		t.Error(response.Status)
+	}
+}
+
+func Test_WaitForStatus_StopsOnceExpiryPassed(t *testing.T) {
+	client := newStatusSequenceClient(time.Now().Add(-time.Minute), "pending")
+
+	response, err := client.WaitForStatus(context.Background(), "test", "completed")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if response.Status != "pending" {
+		t.Error(response.Status)
+	}
+}
+
+func Test_WaitForStatus_ReturnsCancelledError(t *testing.T) {
+	client := newStatusSequenceClient(time.Now().Add(time.Hour), "pending")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	response, err := client.WaitForStatus(ctx, "test", "completed")
+
+	if response != nil {
+		t.Error()
+	}
+	if err == nil || !err.IsCancelledError {
+		t.Error(err)
+	}
+}
+
+func Test_WatchStatus_SendsEachChange(t *testing.T) {
+	client := newStatusSequenceClient(time.Now().Add(time.Hour), "pending", "pending", "in_progress", "in_progress", "completed")
+
+	var changes []StatusChange
+
+	for change := range client.WatchStatus(context.Background(), "test") {
+		changes = append(changes, change)
+	}
+
+	if len(changes) != 3 {
+		t.Error(len(changes))
+		return
+	}
+	if changes[0].PreviousStatus != "" || changes[0].Response.Status != "pending" {
+		t.Error()
+	}
+	if changes[1].PreviousStatus != "pending" || changes[1].Response.Status != "in_progress" {
+		t.Error()
+	}
+	if changes[2].PreviousStatus != "in_progress" || changes[2].Response.Status != "completed" {
+		t.Error()
+	}
+}
+
+func Test_WatchStatus_SendsErrors(t *testing.T) {
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithTransport(newStaticTransport(404, `{"status":"error","msg":"Application not found","data":[]}`)))
+
+	var changes []StatusChange
+
+	for change := range client.WatchStatus(context.Background(), "test") {
+		changes = append(changes, change)
+	}
+
+	if len(changes) != 1 || changes[0].Err == nil || !changes[0].Err.IsRequestRefusedError {
+		t.Error(changes)
+	}
+}
+
+func Test_PollOptions_nextInterval(t *testing.T) {
+	options := PollOptions{MaxInterval: 3 * time.Second, Multiplier: 2}
+
+	if options.nextInterval(time.Second) != 2*time.Second {
+		t.Error()
+	}
+	if options.nextInterval(2*time.Second) != 3*time.Second {
+		t.Error()
+	}
+	if (PollOptions{}).initialInterval() != 2*time.Second {
+		t.Error()
+	}
+}
@@ -0,0 +1,58 @@
+package pasdk
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Matches a UK postcode in upper case with the spaces removed. The outward code is one
+// of the forms A9, A99, A9A, AA9, AA99 or AA9A, using only the letters allowed in each
+// position, and the inward code is a digit followed by two letters.
+var postcodePattern = regexp.MustCompile(
+	"^(?:[A-PR-UWYZ][0-9][0-9]?|[A-PR-UWYZ][A-HK-Y][0-9][0-9]?|[A-PR-UWYZ][0-9][A-HJKPSTUW]|[A-PR-UWYZ][A-HK-Y][0-9][ABEHMNPRVWXY])" +
+		"[0-9][ABD-HJLNP-UW-Z]{2}$")
+
+// Matches a British Forces Post Office postcode, such as "BFPO 123", in upper case with
+// the spaces removed.
+var bfpoPattern = regexp.MustCompile("^BFPO[0-9]{1,4}$")
+
+// NormalisePostcode checks that postcode is a valid UK postcode and returns it in its
+// canonical form, in upper case with a single space before the inward code, such as
+// "SW1A 1AA". Standard postcodes, "GIR 0AA" and British Forces (BFPO) postcodes are
+// accepted. It returns false if postcode isn't valid.
+func NormalisePostcode(postcode string) (string, bool) {
+	compact := strings.ToUpper(strings.Join(strings.Fields(postcode), ""))
+
+	switch {
+	case compact == "GIR0AA":
+		return "GIR 0AA", true
+	case bfpoPattern.MatchString(compact):
+		return "BFPO " + compact[4:], true
+	case postcodePattern.MatchString(compact):
+		return compact[:len(compact)-3] + " " + compact[len(compact)-3:], true
+	}
+
+	return "", false
+}
+
+// Returns the problem with the given field if postcode is empty or isn't a valid UK postcode.
+func checkPostcode(fieldName string, postcode string) *FieldError {
+	if len(postcode) == 0 {
+		return &FieldError{fieldName, FieldErrorRequired, fieldName + " cannot be empty"}
+	}
+
+	if _, valid := NormalisePostcode(postcode); !valid {
+		return &FieldError{fieldName, FieldErrorInvalidPostcode, fieldName + " is not a valid UK postcode"}
+	}
+
+	return nil
+}
+
+// Returns postcode in its canonical form if it is valid, otherwise returns it unchanged.
+func normalisePostcodeIfValid(postcode string) string {
+	if normalised, valid := NormalisePostcode(postcode); valid {
+		return normalised
+	}
+
+	return postcode
+}
@@ -0,0 +1,98 @@
+package pasdk
+
+import (
+	"net/http"
+	"testing"
+)
+
+func Test_NormalisePostcode(t *testing.T) {
+	valid := map[string]string{
+		"SW1A 1AA":   "SW1A 1AA",
+		"sw1a1aa":    "SW1A 1AA",
+		" ec1a  1bb": "EC1A 1BB",
+		"W1A 0AX":    "W1A 0AX",
+		"m11ae":      "M1 1AE",
+		"B33 8TH":    "B33 8TH",
+		"CR2 6XH":    "CR2 6XH",
+		"DN55 1PT":   "DN55 1PT",
+		"gir0aa":     "GIR 0AA",
+		"BFPO 1":     "BFPO 1",
+		"bfpo1234":   "BFPO 1234",
+	}
+
+	for input, expected := range valid {
+		actual, ok := NormalisePostcode(input)
+
+		if !ok || actual != expected {
+			t.Error(input, actual, ok)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"test",
+		"TEST TES",
+		"SW1A",
+		"1AA",
+		"SW1A 1AAA",
+		"QW1 1AA",  // Q can't start a postcode.
+		"AI1 1AA",  // I can't be the second letter.
+		"SW1A 1CA", // C can't appear in the inward code.
+		"BFPO",
+		"BFPO 12345",
+		"SW1A-1AA",
+	}
+
+	for _, input := range invalid {
+		if actual, ok := NormalisePostcode(input); ok {
+			t.Error(input, actual)
+		}
+	}
+}
+
+func Test_checkPostcode(t *testing.T) {
+	if checkPostcode("CustomerPostcode", "").Error() != "CustomerPostcode cannot be empty" {
+		t.Error()
+	}
+	if checkPostcode("CustomerPostcode", "garbage").Error() != "CustomerPostcode is not a valid UK postcode" {
+		t.Error()
+	}
+	if checkPostcode("CustomerPostcode", "sw1a1aa") != nil {
+		t.Error()
+	}
+}
+
+func Test_Preapproval_SendsNormalisedPostcode(t *testing.T) {
+	var postcode string
+	var signatureValid bool
+
+	_, client := newTestServerClient(t, PAAuth{APIKey: "key", APISecret: "secret"},
+		func(writer http.ResponseWriter, request *http.Request) {
+			request.ParseForm()
+			postcode = request.PostForm.Get("postcode")
+
+			params := []requestParam{}
+
+			for _, key := range []string{"addr1", "f_name", "postcode", "s_name"} {
+				params = append(params, requestParam{key, request.PostForm.Get(key)})
+			}
+
+			signatureValid = generateSignature(params, "secret") == request.PostForm.Get("signature")
+
+			writer.Write([]byte(`{"status":"ok","msg":null,"data":{"approved":true}}`))
+		})
+
+	_, err := client.Preapproval(PreapprovalRequest{
+		CustomerFirstName: "Test",
+		CustomerLastName:  "Testington",
+		CustomerAddress1:  "Test House",
+		CustomerPostcode:  "sw1a1aa",
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+	if postcode != "SW1A 1AA" || !signatureValid {
+		t.Error(postcode, signatureValid)
+	}
+}
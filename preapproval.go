@@ -1,5 +1,7 @@
 package pasdk
 
+import "context"
+
 // PreapprovalRequest allows you to check the eligibity of a customer in advance.
 // Success simply means that the customer has passed our internal checks. They
 // will still need to have funds available to cover any deposit payment for
@@ -7,7 +9,7 @@ package pasdk
 type PreapprovalRequest struct {
 	CustomerFirstName string // The customer's first name.
 	CustomerLastName  string // The customer's last name.
-	CustomerPostcode  string // The customer's postode.
+	CustomerPostcode  string // The customer's UK postcode. This is converted to its canonical form, such as "SW1A 1AA", before it is sent.
 	CustomerAddress1  string // The first line of the customer's address.
 }
 
@@ -16,10 +18,35 @@ type PreapprovalResponse struct {
 	Approved bool `json:"approved"` // Whether or not this customer passed the pre-approval checks.
 }
 
-// Fetch executes the request.
-func (request PreapprovalRequest) Fetch() (response *PreapprovalResponse, err *PASDKError) {
+// Fetch executes the request using the credentials passed to Initialise.
+func (request PreapprovalRequest) Fetch() (*PreapprovalResponse, *PASDKError) {
+	return defaultClient.PreapprovalContext(context.Background(), request)
+}
+
+// FetchContext executes the request using the credentials passed to Initialise. The
+// request is abandoned as soon as ctx is cancelled or its deadline passes.
+func (request PreapprovalRequest) FetchContext(ctx context.Context) (*PreapprovalResponse, *PASDKError) {
+	return defaultClient.PreapprovalContext(ctx, request)
+}
+
+// Validate checks the request without sending it, and returns a validation error listing
+// every problem found in FieldErrors, or nil if the request is valid.
+func (request PreapprovalRequest) Validate() *PASDKError {
+	return validatePreapprovalRequest(request)
+}
+
+// Preapproval executes the given request using this client's credentials.
+func (client *Client) Preapproval(request PreapprovalRequest) (*PreapprovalResponse, *PASDKError) {
+	return client.PreapprovalContext(context.Background(), request)
+}
+
+// PreapprovalContext is like Preapproval, but the request is abandoned as soon as ctx is
+// cancelled or its deadline passes.
+func (client *Client) PreapprovalContext(ctx context.Context, request PreapprovalRequest) (response *PreapprovalResponse, err *PASDKError) {
+	defer client.logFailure(ctx, "preapproval", &err)
 	defer catchGenericPanic(&response, &err)
 
+	request.CustomerPostcode = normalisePostcodeIfValid(request.CustomerPostcode)
 	err = validatePreapprovalRequest(request)
 
 	if err != nil {
@@ -27,27 +54,27 @@ func (request PreapprovalRequest) Fetch() (response *PreapprovalResponse, err *P
 	}
 
 	// Alphabetically sorted.
-	requestParams := []string{
-		"addr1=" + request.CustomerAddress1,
-		"f_name=" + request.CustomerFirstName,
-		"postcode=" + request.CustomerPostcode,
-		"s_name=" + request.CustomerLastName,
+	requestParams := []requestParam{
+		{"addr1", request.CustomerAddress1},
+		{"f_name", request.CustomerFirstName},
+		{"postcode", request.CustomerPostcode},
+		{"s_name", request.CustomerLastName},
 	}
 
 	requestParams = removeEmptyParams(requestParams)
 
-	signature := generateSignature(requestParams, userCredentials.APISecret)
+	signature := generateSignature(requestParams, client.credentials.APISecret)
 
-	requestParams = append(requestParams, "api_key="+userCredentials.APIKey)
-	requestParams = append(requestParams, "signature="+signature)
+	requestParams = append(requestParams, requestParam{"api_key", client.credentials.APIKey})
+	requestParams = append(requestParams, requestParam{"signature", signature})
 
-	requestURL, err := getRequestURL()
+	requestURL, err := client.getRequestURL()
 
 	if err != nil {
 		return nil, err.Wrap("failed determining request URL: ")
 	}
 
-	response, err = makeAPIPOSTRequest[PreapprovalResponse](requestParams, requestURL+"preapproval")
+	response, err = makeAPIPOSTRequest[PreapprovalResponse](ctx, client, requestParams, requestURL+"preapproval")
 
 	if err != nil {
 		return nil, err.Wrap("API request failed: ")
@@ -57,21 +84,12 @@ func (request PreapprovalRequest) Fetch() (response *PreapprovalResponse, err *P
 }
 
 func validatePreapprovalRequest(request PreapprovalRequest) (err *PASDKError) {
-	if len(request.CustomerFirstName) == 0 {
-		return buildValidationFailedError("CustomerFirstName cannot be empty")
-	}
-
-	if len(request.CustomerLastName) == 0 {
-		return buildValidationFailedError("CustomerLastName cannot be empty")
-	}
+	validation := validation{}
 
-	if len(request.CustomerAddress1) == 0 {
-		return buildValidationFailedError("CustomerAddress1 cannot be empty")
-	}
-
-	if len(request.CustomerPostcode) == 0 {
-		return buildValidationFailedError("CustomerPostcode cannot be empty")
-	}
+	validation.required("CustomerFirstName", request.CustomerFirstName)
+	validation.required("CustomerLastName", request.CustomerLastName)
+	validation.required("CustomerAddress1", request.CustomerAddress1)
+	validation.check(checkPostcode("CustomerPostcode", request.CustomerPostcode))
 
-	return nil
+	return validation.err()
 }
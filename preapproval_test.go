@@ -13,7 +13,7 @@ func Test_Preapproval(t *testing.T) {
 		CustomerFirstName: "Test",
 		CustomerLastName:  "Testington",
 		CustomerAddress1:  "Test House",
-		CustomerPostcode:  "TEST TES",
+		CustomerPostcode:  "TE1 1ST",
 	}
 
 	response, err := request.Fetch()
@@ -30,29 +30,23 @@ func Test_Preapproval(t *testing.T) {
 func Test_validatePreapprovalRequest(t *testing.T) {
 	request := PreapprovalRequest{}
 
-	if validatePreapprovalRequest(request).Error() != "CustomerFirstName cannot be empty" {
-		t.Error()
-	}
+	checkFieldErrors(t, validatePreapprovalRequest(request), []FieldError{
+		{"CustomerFirstName", FieldErrorRequired, "CustomerFirstName cannot be empty"},
+		{"CustomerLastName", FieldErrorRequired, "CustomerLastName cannot be empty"},
+		{"CustomerAddress1", FieldErrorRequired, "CustomerAddress1 cannot be empty"},
+		{"CustomerPostcode", FieldErrorRequired, "CustomerPostcode cannot be empty"},
+	})
 
 	request.CustomerFirstName = "test"
-
-	if validatePreapprovalRequest(request).Error() != "CustomerLastName cannot be empty" {
-		t.Error()
-	}
-
 	request.CustomerLastName = "test"
-
-	if validatePreapprovalRequest(request).Error() != "CustomerAddress1 cannot be empty" {
-		t.Error()
-	}
-
 	request.CustomerAddress1 = "test"
+	request.CustomerPostcode = "test"
 
-	if validatePreapprovalRequest(request).Error() != "CustomerPostcode cannot be empty" {
-		t.Error()
-	}
+	checkFieldErrors(t, validatePreapprovalRequest(request), []FieldError{
+		{"CustomerPostcode", FieldErrorInvalidPostcode, "CustomerPostcode is not a valid UK postcode"},
+	})
 
-	request.CustomerPostcode = "test"
+	request.CustomerPostcode = "te11st"
 
 	if validatePreapprovalRequest(request) != nil {
 		t.Error()
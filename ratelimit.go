@@ -0,0 +1,229 @@
+package pasdk
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit limits how quickly a Client sends requests to the API, so that bursts of
+// traffic are smoothed out on your side rather than being refused by the API.
+//
+// Requests are limited using a token bucket: the bucket holds up to Burst tokens and is
+// refilled at RequestsPerSecond, and each attempt at sending a request (including retries)
+// takes a token, waiting for one if the bucket is empty.
+type RateLimit struct {
+	RequestsPerSecond float64 // The sustained rate at which requests may be sent. Zero means the rate isn't limited.
+	Burst             int     // How many requests may be sent at once after a quiet period. Values below 1 are treated as 1.
+	MaxConcurrent     int     // The most requests that may be in flight at the same time. Zero means there is no limit.
+}
+
+// WithRateLimit limits the rate and concurrency of all requests sent by the client.
+func WithRateLimit(limit RateLimit) ClientOption {
+	return func(client *Client) {
+		client.rateLimiter = newRateLimiter(limit)
+	}
+}
+
+// WithEndpointRateLimit limits the rate and concurrency of requests sent by the client
+// to a single endpoint, such as "plan" or "status". Requests to the endpoint must also
+// satisfy any limit set with WithRateLimit. This option can be passed once per endpoint.
+func WithEndpointRateLimit(endpointName string, limit RateLimit) ClientOption {
+	return func(client *Client) {
+		if client.endpointRateLimiters == nil {
+			client.endpointRateLimiters = map[string]*rateLimiter{}
+		}
+
+		client.endpointRateLimiters[endpointName] = newRateLimiter(limit)
+	}
+}
+
+// A token bucket combined with a semaphore, shared by every request it applies to.
+type rateLimiter struct {
+	limit RateLimit
+	slots chan struct{} // Holds a value for each request in flight, or nil if concurrency isn't limited.
+
+	mutex       sync.Mutex
+	tokens      float64
+	updated     time.Time // When tokens was last refilled.
+	pausedUntil time.Time // No requests are sent before this time, after the API throttled a request.
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+
+	limiter := &rateLimiter{
+		limit:   limit,
+		tokens:  float64(limit.Burst),
+		updated: time.Now(),
+	}
+
+	if limit.MaxConcurrent > 0 {
+		limiter.slots = make(chan struct{}, limit.MaxConcurrent)
+	}
+
+	return limiter
+}
+
+// Waits until a request may be sent, returning an error if ctx is cancelled first. If
+// no error is returned, release must be called once the request has finished.
+func (limiter *rateLimiter) wait(ctx context.Context) *PASDKError {
+	for {
+		delay := limiter.take(time.Now())
+
+		if delay == 0 {
+			break
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return buildCancelledError("API request was cancelled while waiting for the rate limit: " +
+				ctx.Err().Error()).withCause(ctx.Err())
+		}
+	}
+
+	if limiter.slots == nil {
+		return nil
+	}
+
+	select {
+	case limiter.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		// The token taken above is deliberately not returned, as another request may
+		// already have been told to wait for it.
+		return buildCancelledError("API request was cancelled while waiting for the rate limit: " +
+			ctx.Err().Error()).withCause(ctx.Err())
+	}
+}
+
+// Takes a token if one is available, returning 0. Otherwise returns how long to wait
+// before trying again.
+func (limiter *rateLimiter) take(now time.Time) time.Duration {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if now.Before(limiter.pausedUntil) {
+		return limiter.pausedUntil.Sub(now)
+	}
+
+	if limiter.limit.RequestsPerSecond <= 0 {
+		return 0
+	}
+
+	if now.After(limiter.updated) {
+		elapsed := now.Sub(limiter.updated).Seconds()
+		limiter.tokens = math.Min(limiter.tokens+elapsed*limiter.limit.RequestsPerSecond, float64(limiter.limit.Burst))
+		limiter.updated = now
+	}
+
+	if limiter.tokens >= 1 {
+		limiter.tokens--
+		return 0
+	}
+
+	wait := time.Duration((1 - limiter.tokens) / limiter.limit.RequestsPerSecond * float64(time.Second))
+
+	if wait < time.Millisecond {
+		wait = time.Millisecond
+	}
+
+	return wait
+}
+
+// Marks a request as finished, allowing another to be sent if concurrency is limited.
+func (limiter *rateLimiter) release() {
+	if limiter.slots != nil {
+		<-limiter.slots
+	}
+}
+
+// Stops any more requests being sent until the given time.
+func (limiter *rateLimiter) pause(until time.Time) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if until.After(limiter.pausedUntil) {
+		limiter.pausedUntil = until
+	}
+}
+
+// Returns the rate limiters that apply to requests to the given endpoint, most specific first.
+func (client *Client) rateLimitersFor(endpointName string) []*rateLimiter {
+	var limiters []*rateLimiter
+
+	if limiter := client.endpointRateLimiters[endpointName]; limiter != nil {
+		limiters = append(limiters, limiter)
+	}
+
+	if client.rateLimiter != nil {
+		limiters = append(limiters, client.rateLimiter)
+	}
+
+	return limiters
+}
+
+// Waits until the client's rate limits allow a request to the given endpoint to be sent.
+// If no error is returned, the returned function must be called once the request has finished.
+func (client *Client) waitForRateLimit(ctx context.Context, endpointName string) (func(), *PASDKError) {
+	limiters := client.rateLimitersFor(endpointName)
+
+	release := func() {
+		for _, limiter := range limiters {
+			limiter.release()
+		}
+	}
+
+	for i, limiter := range limiters {
+		if err := limiter.wait(ctx); err != nil {
+			limiters = limiters[:i]
+			release()
+
+			return nil, err
+		}
+	}
+
+	return release, nil
+}
+
+// Holds back further requests to the given endpoint until the time the API asked us to
+// wait for after throttling a request.
+func (client *Client) pauseRateLimit(endpointName string, retryAfter time.Duration) {
+	if retryAfter <= 0 {
+		return
+	}
+
+	until := time.Now().Add(retryAfter)
+
+	for _, limiter := range client.rateLimitersFor(endpointName) {
+		limiter.pause(until)
+	}
+}
+
+// Returns the wait requested by a Retry-After header, which is either a number of seconds
+// or an HTTP date, or 0 if the header is missing or invalid.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if len(header) == 0 {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	date, err := http.ParseTime(header)
+
+	if err != nil || !date.After(now) {
+		return 0
+	}
+
+	return date.Sub(now)
+}
@@ -0,0 +1,220 @@
+package pasdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_rateLimiter_take(t *testing.T) {
+	limiter := newRateLimiter(RateLimit{RequestsPerSecond: 10, Burst: 2})
+	now := limiter.updated
+
+	if limiter.take(now) != 0 || limiter.take(now) != 0 {
+		t.Error("burst should be available immediately")
+	}
+
+	if delay := limiter.take(now); delay != 100*time.Millisecond {
+		t.Error(delay)
+	}
+
+	if delay := limiter.take(now.Add(100 * time.Millisecond)); delay != 0 {
+		t.Error(delay)
+	}
+
+	// The bucket never holds more than Burst tokens.
+	later := now.Add(time.Hour)
+
+	if limiter.take(later) != 0 || limiter.take(later) != 0 || limiter.take(later) == 0 {
+		t.Error("bucket overfilled")
+	}
+}
+
+func Test_rateLimiter_pause(t *testing.T) {
+	limiter := newRateLimiter(RateLimit{})
+	now := time.Now()
+
+	limiter.pause(now.Add(time.Second))
+
+	if delay := limiter.take(now); delay != time.Second {
+		t.Error(delay)
+	}
+
+	if delay := limiter.take(now.Add(time.Second)); delay != 0 {
+		t.Error(delay)
+	}
+}
+
+func Test_WithRateLimit_LimitsConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithRateLimit(RateLimit{MaxConcurrent: 2}),
+		WithTransport(RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+
+			for {
+				seen := atomic.LoadInt32(&maxInFlight)
+
+				if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+					break
+				}
+			}
+
+			time.Sleep(10 * time.Millisecond)
+
+			return newStaticTransport(200, `{"status":"ok","msg":null,"data":{"token":"test","status":"pending"}}`)(request)
+		})))
+
+	var group sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+
+			if _, err := client.Status(StatusRequest{ApplicationToken: "test"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	group.Wait()
+
+	if maxInFlight != 2 {
+		t.Error(maxInFlight)
+	}
+}
+
+func Test_WithEndpointRateLimit_OnlyAppliesToEndpoint(t *testing.T) {
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithEndpointRateLimit("status", RateLimit{RequestsPerSecond: 0.001, Burst: 1}),
+		WithTransport(newStaticTransport(200, `{"status":"ok","msg":null,"data":{"token":"test","status":"pending"}}`)))
+
+	if _, err := client.Status(StatusRequest{ApplicationToken: "test"}); err != nil {
+		t.Error(err)
+	}
+
+	// The bucket is now empty, so the next request has to wait far longer than the context allows.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.StatusContext(ctx, StatusRequest{ApplicationToken: "test"})
+
+	if err == nil || !err.IsCancelledError || err.Endpoint != "status" {
+		t.Error(err)
+	}
+
+	if len(client.rateLimitersFor("account")) != 0 {
+		t.Error("the status limit was applied to account")
+	}
+}
+
+func Test_checkStatusCode_RecognisesThrottling(t *testing.T) {
+	err := checkStatusCode(429, `{"status":"error","msg":"Too many requests","data":[]}`)
+
+	if err == nil || !err.IsThrottledError || !err.IsRequestRefusedError {
+		t.Error(err)
+		return
+	}
+	if err.GetErrorType() != "ThrottledError" || !errors.Is(err, ErrThrottled) || err.APIMessage != "Too many requests" {
+		t.Error(err)
+	}
+
+	if err = checkStatusCode(400, "bad request"); err.IsThrottledError || errors.Is(err, ErrThrottled) {
+		t.Error(err)
+	}
+}
+
+func Test_Retry_HonoursRetryAfter(t *testing.T) {
+	var sent []time.Time
+
+	client, _ := newRetryingTestClient(func(request *http.Request) (*http.Response, error) {
+		sent = append(sent, time.Now())
+
+		if len(sent) == 1 {
+			response, _ := newStaticTransport(429, "slow down")(request)
+			response.Header.Set("Retry-After", "1")
+
+			return response, nil
+		}
+
+		return newStaticTransport(200, `{"status":"ok","msg":null,"data":{"token":"test","status":"pending"}}`)(request)
+	})
+
+	_, err := client.Status(StatusRequest{ApplicationToken: "test"})
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(sent) != 2 || sent[1].Sub(sent[0]) < time.Second {
+		t.Error(sent)
+	}
+}
+
+func Test_Retry_GivesUpWhenRetryAfterExceedsMaxBackoff(t *testing.T) {
+	attempts := 0
+
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MaxBackoff: time.Second}),
+		WithTransport(RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			attempts++
+
+			response, _ := newStaticTransport(429, "slow down")(request)
+			response.Header.Set("Retry-After", "120")
+
+			return response, nil
+		})))
+
+	_, err := client.Status(StatusRequest{ApplicationToken: "test"})
+
+	if err == nil || !err.IsThrottledError || err.RetryAfter != 2*time.Minute {
+		t.Error(err)
+	}
+	if attempts != 1 {
+		t.Error(attempts)
+	}
+}
+
+func Test_Throttling_PausesRateLimiter(t *testing.T) {
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithRateLimit(RateLimit{}),
+		WithTransport(RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			response, _ := newStaticTransport(429, "slow down")(request)
+			response.Header.Set("Retry-After", "60")
+
+			return response, nil
+		})))
+
+	client.Status(StatusRequest{ApplicationToken: "test"})
+
+	if delay := client.rateLimiter.take(time.Now()); delay < 59*time.Second {
+		t.Error(delay)
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]time.Duration{
+		"":                              0,
+		"30":                            30 * time.Second,
+		"-5":                            0,
+		"soon":                          0,
+		"Thu, 01 Jun 2023 12:01:30 GMT": 90 * time.Second,
+		"Thu, 01 Jun 2023 11:59:00 GMT": 0,
+	}
+
+	for header, expected := range tests {
+		if actual := parseRetryAfter(header, now); actual != expected {
+			t.Error(header, actual)
+		}
+	}
+}
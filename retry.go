@@ -0,0 +1,174 @@
+package pasdk
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how a Client retries requests that failed for transient
+// reasons, such as connection errors, 5xx responses or 429 responses.
+//
+// Requests to endpoints that don't change anything ("account", "plan" and "status") are
+// retried after any transient failure: a timeout, a reset or refused connection, or a 5xx
+// or 429 response. Other network failures, such as an invalid TLS certificate, aren't
+// retried as they would only fail again. Requests to the other endpoints ("begin",
+// "preapproval", "update", "capture" and "invoice") are only retried when the request
+// provably never reached the API, for example because the connection could not be
+// opened, so that an application is never begun or captured twice and a customer's
+// credit is never checked twice.
+//
+// When a 429 response has a Retry-After header, the retry waits for as long as the API
+// asked instead of backing off, unless that is longer than MaxBackoff, in which case the
+// request fails straight away with the throttled error.
+type RetryPolicy struct {
+	MaxAttempts    int           // The maximum number of attempts, including the first. Values below 1 are treated as 1, which disables retries.
+	InitialBackoff time.Duration // How long to wait before the first retry.
+	MaxBackoff     time.Duration // The longest time to wait between two attempts. Zero means there is no limit.
+	Multiplier     float64       // How much the wait grows after each retry. Values below 1 are treated as 1.
+	Jitter         float64       // The fraction of each wait, between 0 and 1, that is randomised to avoid many clients retrying in lockstep.
+}
+
+// DefaultRetryPolicy returns a retry policy suitable for most uses: up to 3 attempts,
+// waiting around 250ms and then 500ms between them.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 250 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// WithRetryPolicy makes the client retry failed requests according to the given policy.
+// By default requests are not retried.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(client *Client) {
+		client.retryPolicy = policy
+	}
+}
+
+// Endpoints that don't change any state, so can safely be sent more than once.
+// "preapproval" isn't one of them, as it runs a credit check against the customer.
+var readOnlyEndpoints = map[string]bool{
+	"account": true,
+	"plan":    true,
+	"status":  true,
+}
+
+func (policy RetryPolicy) maxAttempts() int {
+	if policy.MaxAttempts < 1 {
+		return 1
+	}
+
+	return policy.MaxAttempts
+}
+
+// Returns how long to wait after the given attempt (starting from 1) before trying again.
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := math.Max(policy.Multiplier, 1)
+	backoff := float64(policy.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+
+	if policy.MaxBackoff > 0 && backoff > float64(policy.MaxBackoff) {
+		backoff = float64(policy.MaxBackoff)
+	}
+
+	jitter := math.Min(math.Max(policy.Jitter, 0), 1)
+	backoff -= backoff * jitter * rand.Float64()
+
+	return time.Duration(backoff)
+}
+
+// Returns how long to wait after the given attempt failed with err before trying again.
+// If the API asked us to wait for longer than MaxBackoff, false is returned as the request
+// shouldn't be retried.
+func (policy RetryPolicy) nextBackoff(attempt int, err *PASDKError) (time.Duration, bool) {
+	if err.RetryAfter <= 0 {
+		return policy.backoff(attempt), true
+	}
+
+	if policy.MaxBackoff > 0 && err.RetryAfter > policy.MaxBackoff {
+		return 0, false
+	}
+
+	return err.RetryAfter, true
+}
+
+// Returns true if a request to the given endpoint that failed with the given status
+// code or connection error can safely be sent again.
+func isRetryableFailure(endpointName string, statusCode int, err error) bool {
+	if err != nil {
+		return neverReachedServer(err) || (readOnlyEndpoints[endpointName] && isTemporaryNetworkError(err))
+	}
+
+	if statusCode == 429 || (statusCode >= 500 && statusCode < 600) {
+		return readOnlyEndpoints[endpointName]
+	}
+
+	return false
+}
+
+// Returns true if err shows that a request failed before a connection to the
+// server was established, meaning the server can't have seen the request.
+func neverReachedServer(err error) bool {
+	var dnsError *net.DNSError
+
+	if errors.As(err, &dnsError) {
+		return true
+	}
+
+	var opError *net.OpError
+
+	return errors.As(err, &opError) && opError.Op == "dial"
+}
+
+// Returns true if err is a network failure that might not happen again, such as a timeout
+// or a reset connection, rather than one that would keep failing, such as an invalid TLS
+// certificate or a malformed URL.
+func isTemporaryNetworkError(err error) bool {
+	var certificateError x509.CertificateInvalidError
+	var authorityError x509.UnknownAuthorityError
+	var hostnameError x509.HostnameError
+	var recordHeaderError tls.RecordHeaderError
+
+	if errors.As(err, &certificateError) || errors.As(err, &authorityError) || errors.As(err, &hostnameError) ||
+		errors.As(err, &recordHeaderError) {
+		return false
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	var dnsError *net.DNSError
+
+	if errors.As(err, &dnsError) {
+		return dnsError.IsTimeout || dnsError.IsTemporary
+	}
+
+	var netError net.Error
+
+	return errors.As(err, &netError) && netError.Timeout()
+}
+
+// Waits for the given duration, returning early with an error if ctx is cancelled first.
+func sleepContext(ctx context.Context, duration time.Duration) *PASDKError {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return buildCancelledError("API request was cancelled while waiting to retry: " + ctx.Err().Error()).withCause(ctx.Err())
+	}
+}
@@ -0,0 +1,228 @@
+package pasdk
+
+import (
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// Returns a client that sends every request to the given transport, retrying up
+// to 3 times with very short waits, along with a pointer to the number of attempts made.
+func newRetryingTestClient(transport RoundTripperFunc) (*Client, *int) {
+	attempts := 0
+
+	countAttempts := func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			attempts++
+			return next.RoundTrip(request)
+		})
+	}
+
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithTransport(transport),
+		WithMiddleware(countAttempts),
+		WithRetryPolicy(RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+		}))
+
+	return client, &attempts
+}
+
+func Test_Retry_ReadOnlyEndpoint_RetriesServerErrors(t *testing.T) {
+	client, attempts := newRetryingTestClient(newStaticTransport(503, "unavailable"))
+
+	_, err := client.Status(StatusRequest{ApplicationToken: "test"})
+
+	if err == nil || !err.IsUnexpectedError {
+		t.Error(err)
+	}
+	if *attempts != 3 {
+		t.Error(*attempts)
+	}
+}
+
+func Test_Retry_ReadOnlyEndpoint_SucceedsAfterTransientFailure(t *testing.T) {
+	calls := 0
+
+	client, attempts := newRetryingTestClient(func(request *http.Request) (*http.Response, error) {
+		calls++
+
+		if calls == 1 {
+			return newStaticTransport(429, "slow down")(request)
+		}
+
+		return newStaticTransport(200, `{"status":"ok","msg":null,"data":{"token":"test","status":"pending"}}`)(request)
+	})
+
+	response, err := client.Status(StatusRequest{ApplicationToken: "test"})
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if response.Status != "pending" {
+		t.Error()
+	}
+	if *attempts != 2 {
+		t.Error(*attempts)
+	}
+}
+
+func Test_Retry_UnsafeEndpoint_DoesntRetryServerErrors(t *testing.T) {
+	client, attempts := newRetryingTestClient(newStaticTransport(500, "failed"))
+
+	_, err := client.Capture(CaptureRequest{ApplicationToken: "test"})
+
+	if err == nil {
+		t.Error()
+	}
+	if *attempts != 1 {
+		t.Error(*attempts)
+	}
+}
+
+func Test_Retry_UnsafeEndpoint_RetriesConnectionFailures(t *testing.T) {
+	client, attempts := newRetryingTestClient(func(request *http.Request) (*http.Response, error) {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	})
+
+	_, err := client.Capture(CaptureRequest{ApplicationToken: "test"})
+
+	if err == nil || !err.IsUnexpectedError {
+		t.Error(err)
+	}
+	if *attempts != 3 {
+		t.Error(*attempts)
+	}
+}
+
+func Test_Retry_UnsafeEndpoint_DoesntRetryFailuresAfterConnecting(t *testing.T) {
+	client, attempts := newRetryingTestClient(func(request *http.Request) (*http.Response, error) {
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+	})
+
+	_, err := client.Capture(CaptureRequest{ApplicationToken: "test"})
+
+	if err == nil {
+		t.Error()
+	}
+	if *attempts != 1 {
+		t.Error(*attempts)
+	}
+}
+
+func Test_Retry_ReadOnlyEndpoint_OnlyRetriesTemporaryNetworkErrors(t *testing.T) {
+	tests := []struct {
+		err      error
+		attempts int
+	}{
+		{&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, 3},
+		{&net.DNSError{Err: "timed out", IsTimeout: true}, 3},
+		{io.ErrUnexpectedEOF, 3},
+		{x509.UnknownAuthorityError{}, 1},
+		{x509.HostnameError{Host: "example.com"}, 1},
+		{errors.New("unsupported protocol scheme"), 1},
+	}
+
+	for _, test := range tests {
+		client, attempts := newRetryingTestClient(func(request *http.Request) (*http.Response, error) {
+			return nil, test.err
+		})
+
+		_, err := client.Status(StatusRequest{ApplicationToken: "test"})
+
+		if err == nil {
+			t.Error(test.err)
+		}
+		if *attempts != test.attempts {
+			t.Error(test.err, *attempts)
+		}
+	}
+}
+
+func Test_Retry_Preapproval_DoesntRetryFailuresAfterConnecting(t *testing.T) {
+	client, attempts := newRetryingTestClient(func(request *http.Request) (*http.Response, error) {
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	})
+
+	_, err := client.Preapproval(PreapprovalRequest{CustomerFirstName: "Test", CustomerLastName: "Test",
+		CustomerAddress1: "Test House", CustomerPostcode: "SW1A 1AA"})
+
+	if err == nil {
+		t.Error()
+	}
+	if *attempts != 1 {
+		t.Error(*attempts)
+	}
+}
+
+func Test_isTemporaryNetworkError(t *testing.T) {
+	timeout := &url.Error{Op: "Post", URL: "https://example.com", Err: &net.OpError{Op: "read", Err: syscall.ETIMEDOUT}}
+
+	if !isTemporaryNetworkError(timeout) {
+		t.Error(timeout)
+	}
+
+	certificate := &url.Error{Op: "Post", URL: "https://example.com", Err: x509.CertificateInvalidError{Reason: x509.Expired}}
+
+	if isTemporaryNetworkError(certificate) {
+		t.Error(certificate)
+	}
+}
+
+func Test_Retry_DoesntRetryRefusedRequests(t *testing.T) {
+	client, attempts := newRetryingTestClient(newStaticTransport(400, "bad request"))
+
+	_, err := client.Status(StatusRequest{ApplicationToken: "test"})
+
+	if err == nil || !err.IsRequestRefusedError {
+		t.Error(err)
+	}
+	if *attempts != 1 {
+		t.Error(*attempts)
+	}
+}
+
+func Test_RetryPolicy_backoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     300 * time.Millisecond,
+		Multiplier:     2,
+	}
+
+	if policy.backoff(1) != 100*time.Millisecond {
+		t.Error(policy.backoff(1))
+	}
+	if policy.backoff(2) != 200*time.Millisecond {
+		t.Error(policy.backoff(2))
+	}
+	if policy.backoff(3) != 300*time.Millisecond {
+		t.Error(policy.backoff(3))
+	}
+
+	policy.Jitter = 0.5
+
+	for i := 0; i < 100; i++ {
+		backoff := policy.backoff(1)
+
+		if backoff < 50*time.Millisecond || backoff > 100*time.Millisecond {
+			t.Error(backoff)
+		}
+	}
+}
+
+func Test_RetryPolicy_maxAttempts(t *testing.T) {
+	if (RetryPolicy{}).maxAttempts() != 1 {
+		t.Error()
+	}
+	if (RetryPolicy{MaxAttempts: 5}).maxAttempts() != 5 {
+		t.Error()
+	}
+}
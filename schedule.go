@@ -0,0 +1,201 @@
+package pasdk
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+)
+
+// The number of payments made per year for each plan frequency.
+var paymentsPerYear = map[string]int{
+	"weekly":      52,
+	"fortnightly": 26,
+	"monthly":     12,
+}
+
+// CalculateSchedule works out locally what the "plan" endpoint would return for the given
+// plan and amount, for a plan beginning on startDate. This saves a round trip to the API,
+// which is useful when showing something like "4 payments of £x" on many products at once.
+//
+// If the plan requires a deposit, the first payment is taken on startDate and the rest
+// follow at the plan's frequency; otherwise the first payment is one period after startDate.
+// Amounts are split so that they always add up exactly to TotalRepayable, with any spare
+// pence taken in the earliest payments. For plans with an APR above 0 the interest is worked
+// out as a standard amortising loan, which may differ from the API by a few pence, so use
+// Client.VerifySchedule to check the results against the API before relying on them.
+func CalculateSchedule(plan Plan, amount Money, startDate time.Time) (*PlanResponse, *PASDKError) {
+	if amount <= 0 {
+		return nil, buildValidationFailedError("field Amount must be greater than 0")
+	}
+
+	if plan.Instalments <= 0 {
+		return nil, buildValidationFailedError("plan " + plan.Name + " must have at least one instalment")
+	}
+
+	periodsPerYear, exists := paymentsPerYear[plan.Frequency]
+
+	if !exists {
+		return nil, buildValidationFailedError("plan frequency \"" + plan.Frequency + "\" is not supported")
+	}
+
+	apr := 0.0
+
+	if len(plan.APR) > 0 {
+		var err error
+		apr, err = strconv.ParseFloat(plan.APR, 64)
+
+		if err != nil || apr < 0 {
+			return nil, buildValidationFailedError("plan APR \"" + plan.APR + "\" is not a valid percentage")
+		}
+	}
+
+	interest := calculateInterest(amount, apr, periodsPerYear, plan.Instalments, plan.DepositRequired)
+
+	totalRepayable, err := amount.Add(interest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	amounts, err := totalRepayable.Split(plan.Instalments)
+
+	if err != nil {
+		return nil, err
+	}
+
+	firstPayment := 1
+
+	if plan.DepositRequired {
+		firstPayment = 0
+	}
+
+	startDate = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
+	schedule := make([]Repayment, 0, plan.Instalments)
+
+	for i, instalment := range amounts {
+		schedule = append(schedule, Repayment{
+			Date:   addPaymentPeriods(startDate, plan.Frequency, firstPayment+i),
+			Amount: instalment,
+		})
+	}
+
+	return &PlanResponse{
+		PlanName:        plan.Name,
+		Amount:          amount,
+		Interest:        interest,
+		TotalRepayable:  totalRepayable,
+		PaymentSchedule: schedule,
+	}, nil
+}
+
+// Returns the interest payable on a loan of the given amount at the given APR (as a
+// percentage), repaid in equal instalments at the given number of payments per year.
+func calculateInterest(amount Money, apr float64, periodsPerYear int, instalments int, depositRequired bool) Money {
+	if apr == 0 {
+		return 0
+	}
+
+	// UK APRs are effective annual rates, so convert to the equivalent rate per payment.
+	rate := math.Pow(1+apr/100, 1/float64(periodsPerYear)) - 1
+	payment := float64(amount) * rate / (1 - math.Pow(1+rate, -float64(instalments)))
+
+	// When the first payment is taken straight away, each payment is made a period earlier.
+	if depositRequired {
+		payment /= 1 + rate
+	}
+
+	return Money(math.Round(payment*float64(instalments))) - amount
+}
+
+// Returns the date the given number of payment periods after start.
+func addPaymentPeriods(start time.Time, frequency string, periods int) time.Time {
+	switch frequency {
+	case "weekly":
+		return start.AddDate(0, 0, 7*periods)
+	case "fortnightly":
+		return start.AddDate(0, 0, 14*periods)
+	}
+
+	// Payments due on a day the month doesn't have, such as the 31st, are taken on the
+	// last day of the month instead of spilling over into the next one.
+	firstOfMonth := time.Date(start.Year(), start.Month()+time.Month(periods), 1, 0, 0, 0, 0, start.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := start.Day()
+
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, 0, 0, 0, 0, start.Location())
+}
+
+// ScheduleComparison is the result of checking a calculated schedule against the API.
+type ScheduleComparison struct {
+	Calculated  *PlanResponse // The schedule worked out by CalculateSchedule.
+	Live        *PlanResponse // The schedule returned by the "plan" endpoint.
+	Differences []string      // A description of each way the two schedules differ. This is empty if they match.
+}
+
+// Matches returns true if the calculated schedule is the same as the one returned by the API.
+func (comparison ScheduleComparison) Matches() bool {
+	return len(comparison.Differences) == 0
+}
+
+// VerifySchedule calculates a schedule for the given plan and amount beginning today, and
+// compares it with the schedule returned by the "plan" endpoint for the same plan and amount.
+func (client *Client) VerifySchedule(ctx context.Context, plan Plan, amount Money) (*ScheduleComparison, *PASDKError) {
+	calculated, err := CalculateSchedule(plan, amount, time.Now())
+
+	if err != nil {
+		return nil, err.Wrap("failed calculating schedule: ")
+	}
+
+	live, err := client.PlanContext(ctx, PlanRequest{Amount: amount, PlanID: &plan.ID})
+
+	if err != nil {
+		return nil, err.Wrap("failed fetching schedule: ")
+	}
+
+	return &ScheduleComparison{
+		Calculated:  calculated,
+		Live:        live,
+		Differences: compareSchedules(*calculated, *live),
+	}, nil
+}
+
+func compareSchedules(calculated PlanResponse, live PlanResponse) []string {
+	differences := []string{}
+
+	if calculated.Interest != live.Interest {
+		differences = append(differences, "interest is "+calculated.Interest.String()+
+			" but the API returned "+live.Interest.String())
+	}
+
+	if calculated.TotalRepayable != live.TotalRepayable {
+		differences = append(differences, "total repayable is "+calculated.TotalRepayable.String()+
+			" but the API returned "+live.TotalRepayable.String())
+	}
+
+	if len(calculated.PaymentSchedule) != len(live.PaymentSchedule) {
+		return append(differences, "there are "+toString(len(calculated.PaymentSchedule))+
+			" payments but the API returned "+toString(len(live.PaymentSchedule)))
+	}
+
+	for i, repayment := range calculated.PaymentSchedule {
+		liveRepayment := live.PaymentSchedule[i]
+		payment := "payment " + toString(i+1)
+
+		if repayment.Amount != liveRepayment.Amount {
+			differences = append(differences, payment+" is "+repayment.Amount.String()+
+				" but the API returned "+liveRepayment.Amount.String())
+		}
+
+		if repayment.Date.Format("2006-01-02") != liveRepayment.Date.Format("2006-01-02") {
+			differences = append(differences, payment+" is due on "+repayment.Date.Format("2006-01-02")+
+				" but the API returned "+liveRepayment.Date.Format("2006-01-02"))
+		}
+	}
+
+	return differences
+}
@@ -0,0 +1,156 @@
+package pasdk
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_CalculateSchedule_InterestFree(t *testing.T) {
+	startDate := time.Date(2019, 2, 12, 15, 30, 0, 0, time.UTC)
+
+	plan := Plan{Name: "4-Payment", Instalments: 4, Frequency: "monthly", APR: "0"}
+
+	response, err := CalculateSchedule(plan, 50001, startDate)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if response.PlanName != "4-Payment" || response.Amount != 50001 {
+		t.Error()
+	}
+	if response.Interest != 0 || response.TotalRepayable != 50001 {
+		t.Error()
+	}
+
+	expectedDates := []string{"2019-03-12", "2019-04-12", "2019-05-12", "2019-06-12"}
+	expectedAmounts := []Money{12501, 12500, 12500, 12500}
+
+	for i, repayment := range response.PaymentSchedule {
+		if repayment.Date.Format("2006-01-02") != expectedDates[i] {
+			t.Error(i, repayment.Date)
+		}
+		if repayment.Amount != expectedAmounts[i] {
+			t.Error(i, repayment.Amount)
+		}
+	}
+}
+
+func Test_CalculateSchedule_DepositTakenOnStartDate(t *testing.T) {
+	startDate := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	plan := Plan{Name: "3-Payment", Instalments: 3, Frequency: "monthly", APR: "0", DepositRequired: true}
+
+	response, err := CalculateSchedule(plan, 30000, startDate)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expectedDates := []string{"2024-01-31", "2024-02-29", "2024-03-31"}
+
+	for i, repayment := range response.PaymentSchedule {
+		if repayment.Date.Format("2006-01-02") != expectedDates[i] {
+			t.Error(i, repayment.Date)
+		}
+		if repayment.Amount != 10000 {
+			t.Error(i, repayment.Amount)
+		}
+	}
+}
+
+func Test_CalculateSchedule_WithInterest(t *testing.T) {
+	plan := Plan{Name: "12-Payment", Instalments: 12, Frequency: "monthly", APR: "10"}
+
+	response, err := CalculateSchedule(plan, 100000, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// A £1,000 loan at 10% APR over 12 monthly payments costs about £52 in interest.
+	if response.Interest < 5000 || response.Interest > 5400 {
+		t.Error(response.Interest)
+	}
+
+	total := Money(0)
+
+	for _, repayment := range response.PaymentSchedule {
+		total += repayment.Amount
+	}
+
+	if total != response.TotalRepayable || response.TotalRepayable != response.Amount+response.Interest {
+		t.Error()
+	}
+}
+
+func Test_CalculateSchedule_Weekly(t *testing.T) {
+	plan := Plan{Instalments: 3, Frequency: "weekly", APR: "0"}
+
+	response, _ := CalculateSchedule(plan, 300, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if response.PaymentSchedule[2].Date.Format("2006-01-02") != "2024-01-22" {
+		t.Error(response.PaymentSchedule[2].Date)
+	}
+}
+
+func Test_CalculateSchedule_RejectsInvalidInput(t *testing.T) {
+	plan := Plan{Instalments: 4, Frequency: "monthly", APR: "0"}
+
+	if _, err := CalculateSchedule(plan, 0, time.Now()); err == nil || !err.IsValidationFailedError {
+		t.Error()
+	}
+
+	plan.Frequency = "yearly"
+
+	if _, err := CalculateSchedule(plan, 100, time.Now()); err == nil {
+		t.Error()
+	}
+
+	plan.Frequency = "monthly"
+	plan.APR = "abc"
+
+	if _, err := CalculateSchedule(plan, 100, time.Now()); err == nil {
+		t.Error()
+	}
+
+	plan.APR = "0"
+	plan.Instalments = 0
+
+	if _, err := CalculateSchedule(plan, 100, time.Now()); err == nil {
+		t.Error()
+	}
+}
+
+func Test_VerifySchedule_ReportsDifferences(t *testing.T) {
+	if shouldRunIntegrationTests() {
+		return
+	}
+
+	plan := Plan{ID: 1, Name: "4-Payment", Instalments: 4, Frequency: "monthly", APR: "0"}
+
+	// The mock API always returns a schedule starting in 2019.
+	comparison, err := DefaultClient().VerifySchedule(context.Background(), plan, 50000)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if comparison.Matches() {
+		t.Error()
+	}
+	if len(comparison.Differences) != 4 {
+		t.Error(comparison.Differences)
+	}
+
+	calculated, _ := CalculateSchedule(plan, 50000, time.Date(2019, 2, 12, 0, 0, 0, 0, time.UTC))
+
+	if len(compareSchedules(*calculated, *comparison.Live)) != 0 {
+		t.Error(compareSchedules(*calculated, *comparison.Live))
+	}
+}
@@ -1,6 +1,7 @@
 package pasdk
 
 import (
+	"context"
 	"time"
 )
 
@@ -11,18 +12,42 @@ type StatusRequest struct {
 
 // StatusResponse contains the data returned by a successful call to the "status" endpoint.
 type StatusResponse struct {
-	ApplicationToken       string    `json:"token"`            // The token representing this application.
-	Status                 string    `json:"status"`           // The status of this application.
-	Amount                 int       `json:"amount"`           // The amount being applied for, in pence.
-	ExpiresAt              time.Time `json:"expires_at"`       // The time this application expires.
-	PaymentAssistReference string    `json:"pa_ref"`           // Payment Assist's reference for this application. This may be empty as a reference is not generated until the finance facility or payment is successfully created (once an application moves to a "completed" status).
-	RequriesInvoice        bool      `json:"requires_invoice"` // Whether an invoice needs to be uploaded for this application before funds will be released to the merchant.
-	HasInvoice             bool      `json:"has_invoice"`      // Whether an invoice has been uploaded for this application.
-	LastAccessedAt         time.Time `json:"last_accessed_at"` // The last time the customer accessed the application.
+	ApplicationToken       string            `json:"token"`            // The token representing this application.
+	Status                 ApplicationStatus `json:"status"`           // The status of this application.
+	Amount                 Money             `json:"amount"`           // The amount being applied for, in pence.
+	ExpiresAt              time.Time         `json:"expires_at"`       // The time this application expires.
+	PaymentAssistReference string            `json:"pa_ref"`           // Payment Assist's reference for this application. This may be empty as a reference is not generated until the finance facility or payment is successfully created (once an application moves to a "completed" status).
+	RequriesInvoice        bool              `json:"requires_invoice"` // Whether an invoice needs to be uploaded for this application before funds will be released to the merchant.
+	HasInvoice             bool              `json:"has_invoice"`      // Whether an invoice has been uploaded for this application.
+	LastAccessedAt         time.Time         `json:"last_accessed_at"` // The last time the customer accessed the application.
 }
 
-// Fetch executes the request.
-func (request StatusRequest) Fetch() (response *StatusResponse, err *PASDKError) {
+// Fetch executes the request using the credentials passed to Initialise.
+func (request StatusRequest) Fetch() (*StatusResponse, *PASDKError) {
+	return defaultClient.StatusContext(context.Background(), request)
+}
+
+// FetchContext executes the request using the credentials passed to Initialise. The
+// request is abandoned as soon as ctx is cancelled or its deadline passes.
+func (request StatusRequest) FetchContext(ctx context.Context) (*StatusResponse, *PASDKError) {
+	return defaultClient.StatusContext(ctx, request)
+}
+
+// Validate checks the request without sending it, and returns a validation error listing
+// every problem found in FieldErrors, or nil if the request is valid.
+func (request StatusRequest) Validate() *PASDKError {
+	return validateStatusRequest(request)
+}
+
+// Status executes the given request using this client's credentials.
+func (client *Client) Status(request StatusRequest) (*StatusResponse, *PASDKError) {
+	return client.StatusContext(context.Background(), request)
+}
+
+// StatusContext is like Status, but the request is abandoned as soon as ctx is
+// cancelled or its deadline passes.
+func (client *Client) StatusContext(ctx context.Context, request StatusRequest) (response *StatusResponse, err *PASDKError) {
+	defer client.logFailure(ctx, "status", &err)
 	defer catchGenericPanic(&response, &err)
 
 	err = validateStatusRequest(request)
@@ -32,24 +57,24 @@ func (request StatusRequest) Fetch() (response *StatusResponse, err *PASDKError)
 	}
 
 	// Alphabetically sorted.
-	requestParams := []string{
-		"token=" + request.ApplicationToken,
+	requestParams := []requestParam{
+		{"token", request.ApplicationToken},
 	}
 
 	requestParams = removeEmptyParams(requestParams)
 
-	signature := generateSignature(requestParams, userCredentials.APISecret)
+	signature := generateSignature(requestParams, client.credentials.APISecret)
 
-	requestParams = append(requestParams, "api_key="+userCredentials.APIKey)
-	requestParams = append(requestParams, "signature="+signature)
+	requestParams = append(requestParams, requestParam{"api_key", client.credentials.APIKey})
+	requestParams = append(requestParams, requestParam{"signature", signature})
 
-	requestURL, err := getRequestURL()
+	requestURL, err := client.getRequestURL()
 
 	if err != nil {
 		return nil, err.Wrap("failed determining request URL: ")
 	}
 
-	response, err = makeAPIGETRequest[StatusResponse](requestParams, requestURL+"status")
+	response, err = makeAPIGETRequest[StatusResponse](ctx, client, requestParams, requestURL+"status")
 
 	if err != nil {
 		return nil, err.Wrap("API request failed: ")
@@ -59,9 +84,9 @@ func (request StatusRequest) Fetch() (response *StatusResponse, err *PASDKError)
 }
 
 func validateStatusRequest(request StatusRequest) (err *PASDKError) {
-	if len(request.ApplicationToken) == 0 {
-		return buildValidationFailedError("ApplicationToken cannot be empty")
-	}
+	validation := validation{}
+
+	validation.required("ApplicationToken", request.ApplicationToken)
 
-	return nil
+	return validation.err()
 }
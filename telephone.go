@@ -0,0 +1,106 @@
+package pasdk
+
+import "strings"
+
+// PhoneNumber is a UK telephone number parsed by ParsePhoneNumber.
+type PhoneNumber struct {
+	E164     string // The number in international E.164 format, such as "+447700900123".
+	National string // The number in national format without spaces, such as "07700900123".
+	IsMobile bool   // Whether the number is a mobile number, so can receive SMS messages.
+}
+
+// ParsePhoneNumber parses a UK telephone number written in any of the usual ways, such
+// as "07700 900123", "+44 7700 900123", "+44 (0)7700 900123" or "0044 7700-900123". The
+// country code may also be written without the "+", as in "44 7700 900123". It returns
+// false if number isn't a valid UK number.
+func ParsePhoneNumber(number string) (PhoneNumber, bool) {
+	national := compactPhoneNumber(number)
+
+	switch {
+	case strings.HasPrefix(national, "+44"):
+		national = "0" + national[3:]
+	case strings.HasPrefix(national, "0044"):
+		national = "0" + national[4:]
+	case strings.HasPrefix(national, "44") && len(national) == 12:
+		// The country code without the "+". UK numbers are 10 digits long without their
+		// leading 0, so this can't be mistaken for a national number.
+		national = "0" + national[2:]
+	}
+
+	if len(national) != 11 || national[0] != '0' || !isDigits(national) {
+		return PhoneNumber{}, false
+	}
+
+	// Numbers starting 00 are international, and 04 and 06 aren't in use.
+	if strings.ContainsRune("046", rune(national[1])) {
+		return PhoneNumber{}, false
+	}
+
+	return PhoneNumber{
+		E164:     "+44" + national[1:],
+		National: national,
+		IsMobile: isMobileNumber(national),
+	}, true
+}
+
+// Removes the spaces and punctuation people use when writing phone numbers, along with
+// the "(0)" often written after the country code.
+func compactPhoneNumber(number string) string {
+	number = strings.ReplaceAll(number, "(0)", "")
+
+	return strings.Map(func(character rune) rune {
+		if strings.ContainsRune(" \t-.()", character) {
+			return -1
+		}
+
+		return character
+	}, number)
+}
+
+// Returns true if national, an 11 digit UK number, is a mobile number. Mobile numbers
+// start with 07, except for 070 (personal numbers) and 076 (pagers), although 07624 is
+// used for mobiles on the Isle of Man.
+func isMobileNumber(national string) bool {
+	if !strings.HasPrefix(national, "07") {
+		return false
+	}
+
+	if strings.HasPrefix(national, "07624") {
+		return true
+	}
+
+	return national[2] != '0' && national[2] != '6'
+}
+
+// Returns the problem with the given field if the telephone number needed to send the
+// customer an SMS is missing, invalid or isn't a mobile number.
+func checkSMSTelephone(fieldName string, telephone *string) *FieldError {
+	if telephone == nil || len(*telephone) == 0 {
+		return &FieldError{fieldName, FieldErrorRequired, fieldName + " cannot be empty if SendSMS is true"}
+	}
+
+	number, valid := ParsePhoneNumber(*telephone)
+
+	if !valid {
+		return &FieldError{fieldName, FieldErrorInvalidTelephone, fieldName + " is not a valid UK telephone number"}
+	}
+
+	if !number.IsMobile {
+		return &FieldError{fieldName, FieldErrorNotMobile, fieldName + " must be a UK mobile number if SendSMS is true"}
+	}
+
+	return nil
+}
+
+// Returns telephone in E.164 format if it is a valid UK number, otherwise returns it unchanged.
+func normaliseTelephoneIfValid(telephone *string) *string {
+	if telephone == nil {
+		return nil
+	}
+
+	if number, valid := ParsePhoneNumber(*telephone); valid {
+		return &number.E164
+	}
+
+	return telephone
+}
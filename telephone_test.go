@@ -0,0 +1,77 @@
+package pasdk
+
+import "testing"
+
+func Test_ParsePhoneNumber(t *testing.T) {
+	tests := map[string]PhoneNumber{
+		"07700 900123":        {E164: "+447700900123", National: "07700900123", IsMobile: true},
+		"07700900123":         {E164: "+447700900123", National: "07700900123", IsMobile: true},
+		"+44 7700 900123":     {E164: "+447700900123", National: "07700900123", IsMobile: true},
+		"+44 (0)7700 900123":  {E164: "+447700900123", National: "07700900123", IsMobile: true},
+		"0044 7700-900123":    {E164: "+447700900123", National: "07700900123", IsMobile: true},
+		"44 7700 900123":      {E164: "+447700900123", National: "07700900123", IsMobile: true},
+		"447700900123":        {E164: "+447700900123", National: "07700900123", IsMobile: true},
+		"44 (0)20 7946 0000":  {E164: "+442079460000", National: "02079460000", IsMobile: false},
+		"(07700) 900.123":     {E164: "+447700900123", National: "07700900123", IsMobile: true},
+		"07624 123456":        {E164: "+447624123456", National: "07624123456", IsMobile: true},
+		"020 7946 0000":       {E164: "+442079460000", National: "02079460000", IsMobile: false},
+		"+44 (0)161 496 0000": {E164: "+441614960000", National: "01614960000", IsMobile: false},
+		"0800 123 4567":       {E164: "+448001234567", National: "08001234567", IsMobile: false},
+		"07010 123456":        {E164: "+447010123456", National: "07010123456", IsMobile: false},
+		"07600 123456":        {E164: "+447600123456", National: "07600123456", IsMobile: false},
+	}
+
+	for input, expected := range tests {
+		actual, ok := ParsePhoneNumber(input)
+
+		if !ok || actual != expected {
+			t.Error(input, actual, ok)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"test",
+		"0770090012",   // Too short.
+		"077009001234", // Too long.
+		"+33 6 12 34 56 78",
+		"0044 0770 090012",
+		"04700 900123",
+		"07700 9001x3",
+		"77009001234",
+		"44 0770 090012",
+		"4477009001234", // Too long.
+	}
+
+	for _, input := range invalid {
+		if actual, ok := ParsePhoneNumber(input); ok {
+			t.Error(input, actual)
+		}
+	}
+}
+
+func Test_applyBeginDefaults_NormalisesTelephone(t *testing.T) {
+	trueValue := true
+	telephone := "+44 (0)7700 900123"
+
+	request := applyBeginDefaults(BeginRequest{CustomerTelephone: &telephone, SendSMS: &trueValue})
+
+	if *request.CustomerTelephone != "+447700900123" || telephone != "+44 (0)7700 900123" {
+		t.Error(*request.CustomerTelephone)
+	}
+
+	// The number is only needed in a particular format when sending an SMS.
+	request = applyBeginDefaults(BeginRequest{CustomerTelephone: &telephone})
+
+	if *request.CustomerTelephone != telephone {
+		t.Error(*request.CustomerTelephone)
+	}
+
+	international := "+33 6 12 34 56 78"
+
+	request = applyBeginDefaults(BeginRequest{CustomerTelephone: &international, SendSMS: &trueValue})
+
+	if *request.CustomerTelephone != international {
+		t.Error(*request.CustomerTelephone)
+	}
+}
@@ -1,6 +1,7 @@
 package pasdk
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"os"
@@ -39,6 +40,16 @@ func getRandomID() string {
 	return hex.EncodeToString(randomBytes)[:10]
 }
 
+// Like getMockAPIResponse, but fails the same way a real request would if ctx
+// has already been cancelled.
+func getMockAPIResponseContext[T interface{}](ctx context.Context, endpoint string) (*T, *PASDKError) {
+	if ctx.Err() != nil {
+		return nil, buildCancelledError("API request was cancelled: " + ctx.Err().Error()).withCause(ctx.Err())
+	}
+
+	return getMockAPIResponse[T](endpoint)
+}
+
 func getMockAPIResponse[T interface{}](endpoint string) (*T, *PASDKError) {
 	// If this is a GET request then the endpoint will have parameters on it. Take them
 	// off so we can match on the actual endpoint.
@@ -0,0 +1,72 @@
+package pasdk
+
+import (
+	"context"
+	"net/http"
+)
+
+// Tracer receives a span for every attempt the SDK makes at sending a request to the
+// API, so that the time spent calling Payment Assist shows up in your distributed traces.
+// It has no dependencies, so it can be adapted to OpenTelemetry or any other tracing
+// library; see the contrib/otelpasdk module for an OpenTelemetry adapter.
+type Tracer interface {
+	// StartSpan is called just before a request is sent. The returned context is used
+	// for the HTTP request, so trace headers added by instrumented transports belong
+	// to the span.
+	StartSpan(ctx context.Context, info SpanInfo) (context.Context, Span)
+}
+
+// Span is a single attempt at sending a request to the API, started by a Tracer.
+type Span interface {
+	// End is called once the attempt has finished, whether or not it succeeded.
+	End(result SpanResult)
+}
+
+// SpanInfo describes the request a span was started for.
+type SpanInfo struct {
+	Endpoint string // The name of the endpoint, such as "begin".
+	Method   string // The HTTP method, such as "POST".
+	Attempt  int    // 1 for the first attempt at sending the request, 2 for the first retry, and so on.
+}
+
+// SpanResult describes how an attempt at sending a request ended.
+type SpanResult struct {
+	StatusCode int         // The HTTP status code of the response, or 0 if no response was received.
+	ErrorType  string      // The result of GetErrorType if the attempt failed, otherwise an empty string.
+	Err        *PASDKError // The error the attempt failed with, or nil if it succeeded.
+}
+
+// WithTracer makes the client start a span using the given Tracer for every attempt
+// at sending a request to the API.
+func WithTracer(tracer Tracer) ClientOption {
+	return func(client *Client) {
+		client.tracer = tracer
+	}
+}
+
+// Starts a span for the request if the client has a tracer. The request is given the
+// span's context. The returned function ends the span.
+func (client *Client) startSpan(request **http.Request, endpointName string,
+	attempt int) func(statusCode int, err *PASDKError) {
+	if client.tracer == nil {
+		return func(int, *PASDKError) {}
+	}
+
+	ctx, span := client.tracer.StartSpan((*request).Context(), SpanInfo{
+		Endpoint: endpointName,
+		Method:   (*request).Method,
+		Attempt:  attempt,
+	})
+
+	*request = (*request).WithContext(ctx)
+
+	return func(statusCode int, err *PASDKError) {
+		result := SpanResult{StatusCode: statusCode, Err: err}
+
+		if err != nil {
+			result.ErrorType = err.GetErrorType()
+		}
+
+		span.End(result)
+	}
+}
@@ -0,0 +1,98 @@
+package pasdk
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type testSpanKey struct{}
+
+// Records every span it starts.
+type testTracer struct {
+	infos   []SpanInfo
+	results []SpanResult
+}
+
+type testSpan struct {
+	tracer *testTracer
+}
+
+func (tracer *testTracer) StartSpan(ctx context.Context, info SpanInfo) (context.Context, Span) {
+	tracer.infos = append(tracer.infos, info)
+
+	return context.WithValue(ctx, testSpanKey{}, info.Attempt), testSpan{tracer}
+}
+
+func (span testSpan) End(result SpanResult) {
+	span.tracer.results = append(span.tracer.results, result)
+}
+
+func Test_WithTracer_StartsSpanForEachAttempt(t *testing.T) {
+	tracer := &testTracer{}
+	spanAttempts := []interface{}{}
+	responses := []int{503, 200}
+
+	transport := RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		spanAttempts = append(spanAttempts, request.Context().Value(testSpanKey{}))
+		statusCode := responses[0]
+		responses = responses[1:]
+
+		return newStaticTransport(statusCode, `{"status":"ok","msg":null,"data":{"token":"test","status":"pending"}}`)(request)
+	})
+
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithTransport(transport),
+		WithTracer(tracer),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}))
+
+	_, err := client.Status(StatusRequest{ApplicationToken: "test"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tracer.infos) != 2 || len(tracer.results) != 2 {
+		t.Fatal(tracer.infos, tracer.results)
+	}
+
+	if tracer.infos[0] != (SpanInfo{Endpoint: "status", Method: "GET", Attempt: 1}) ||
+		tracer.infos[1] != (SpanInfo{Endpoint: "status", Method: "GET", Attempt: 2}) {
+		t.Error(tracer.infos)
+	}
+
+	first := tracer.results[0]
+
+	if first.StatusCode != 503 || first.ErrorType != "UnexpectedError" || first.Err == nil || first.Err.Endpoint != "status" {
+		t.Error(first)
+	}
+
+	if tracer.results[1] != (SpanResult{StatusCode: 200}) {
+		t.Error(tracer.results[1])
+	}
+
+	// The request is sent with the span's context.
+	if len(spanAttempts) != 2 || spanAttempts[0] != 1 || spanAttempts[1] != 2 {
+		t.Error(spanAttempts)
+	}
+}
+
+func Test_WithTracer_RecordsRefusedRequests(t *testing.T) {
+	tracer := &testTracer{}
+
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		WithTransport(newStaticTransport(400, `{"status":"error","msg":"Invalid amount","data":[]}`)),
+		WithTracer(tracer))
+
+	_, err := client.Plan(PlanRequest{Amount: 100})
+
+	if err == nil || len(tracer.results) != 1 {
+		t.Fatal(err)
+	}
+
+	if tracer.infos[0].Method != "POST" || tracer.results[0].ErrorType != "RequestRefusedError" ||
+		tracer.results[0].StatusCode != 400 {
+		t.Error(tracer.infos[0], tracer.results[0])
+	}
+}
@@ -1,6 +1,7 @@
 package pasdk
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"strconv"
@@ -11,7 +12,7 @@ type UpdateRequest struct {
 	ApplicationToken string  // The token you received when calling the "begin" endpoint.
 	OrderID          *string // Your new order ID. You can only change this if the application's status is "completed".
 	ExpiresIn        *int    // The new expiry time for this appication in seconds from now. Setting this to 0 will instantly expire the application. You can only change this if the application's status is "pending", "in_progress" or "pending_capture".
-	Amount           *int    // The new amount for this application in pence. You can only change this if the application's status is "pending", "in_progress" or "pending_capture". The new amount must be less than the current amount.
+	Amount           *Money  // The new amount for this application in pence. You can only change this if the application's status is "pending", "in_progress" or "pending_capture". The new amount must be less than the current amount.
 }
 
 // UpdateResponse contains the data returned by a successful call to the "update" endpoint.
@@ -19,7 +20,7 @@ type UpdateResponse struct {
 	ApplicationToken string  `json:"token"`    // The token representing this application.
 	OrderID          *string `json:"order_id"` // The new order ID you requested, if any.
 	ExpiresIn        *int    `json:"expiry"`   // The new expiry time you requested in seconds, if any.
-	Amount           *int    `json:"amount"`   // The new amount you requested in pence, if any.
+	Amount           *Money  `json:"amount"`   // The new amount you requested in pence, if any.
 }
 
 func (response *UpdateResponse) UnmarshalJSON(data []byte) error {
@@ -55,14 +56,39 @@ func (response *UpdateResponse) UnmarshalJSON(data []byte) error {
 			return errors.New("failed to convert string to integer: " + err.Error())
 		}
 
-		response.Amount = &amount
+		response.Amount = (*Money)(&amount)
 	}
 
 	return nil
 }
 
-// Fetch executes the request.
-func (request UpdateRequest) Fetch() (response *UpdateResponse, err *PASDKError) {
+// Fetch executes the request using the credentials passed to Initialise.
+func (request UpdateRequest) Fetch() (*UpdateResponse, *PASDKError) {
+	return defaultClient.UpdateContext(context.Background(), request)
+}
+
+// FetchContext executes the request using the credentials passed to Initialise. The
+// request is abandoned as soon as ctx is cancelled or its deadline passes.
+func (request UpdateRequest) FetchContext(ctx context.Context) (*UpdateResponse, *PASDKError) {
+	return defaultClient.UpdateContext(ctx, request)
+}
+
+// Validate checks the request without sending it, and returns a validation error listing
+// every problem found in FieldErrors, or nil if the request is valid. Use CheckAgainstStatus
+// to check whether the update can be applied to the application as it currently stands.
+func (request UpdateRequest) Validate() *PASDKError {
+	return validateUpdateRequest(request)
+}
+
+// Update executes the given request using this client's credentials.
+func (client *Client) Update(request UpdateRequest) (*UpdateResponse, *PASDKError) {
+	return client.UpdateContext(context.Background(), request)
+}
+
+// UpdateContext is like Update, but the request is abandoned as soon as ctx is
+// cancelled or its deadline passes.
+func (client *Client) UpdateContext(ctx context.Context, request UpdateRequest) (response *UpdateResponse, err *PASDKError) {
+	defer client.logFailure(ctx, "update", &err)
 	defer catchGenericPanic(&response, &err)
 
 	err = validateUpdateRequest(request)
@@ -71,28 +97,36 @@ func (request UpdateRequest) Fetch() (response *UpdateResponse, err *PASDKError)
 		return nil, err.Wrap("request is invalid: ")
 	}
 
+	if client.statusPreflight {
+		err = client.checkApplicationStatus(ctx, request.ApplicationToken, request.CheckAgainstStatus)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Alphabetically sorted.
-	requestParams := []string{
-		"amount=" + toString(request.Amount),
-		"expiry=" + toString(request.ExpiresIn),
-		"order_id=" + toString(request.OrderID),
-		"token=" + request.ApplicationToken,
+	requestParams := []requestParam{
+		{"amount", toString(request.Amount)},
+		{"expiry", toString(request.ExpiresIn)},
+		{"order_id", toString(request.OrderID)},
+		{"token", request.ApplicationToken},
 	}
 
 	requestParams = removeEmptyParams(requestParams)
 
-	signature := generateSignature(requestParams, userCredentials.APISecret)
+	signature := generateSignature(requestParams, client.credentials.APISecret)
 
-	requestParams = append(requestParams, "api_key="+userCredentials.APIKey)
-	requestParams = append(requestParams, "signature="+signature)
+	requestParams = append(requestParams, requestParam{"api_key", client.credentials.APIKey})
+	requestParams = append(requestParams, requestParam{"signature", signature})
 
-	requestURL, err := getRequestURL()
+	requestURL, err := client.getRequestURL()
 
 	if err != nil {
 		return nil, err.Wrap("failed determining request URL: ")
 	}
 
-	response, err = makeAPIPOSTRequest[UpdateResponse](requestParams, requestURL+"update")
+	response, err = makeAPIPOSTRequest[UpdateResponse](ctx, client, requestParams, requestURL+"update")
 
 	if err != nil {
 		return nil, err.Wrap("API request failed: ")
@@ -102,9 +136,39 @@ func (request UpdateRequest) Fetch() (response *UpdateResponse, err *PASDKError)
 }
 
 func validateUpdateRequest(request UpdateRequest) (err *PASDKError) {
-	if len(request.ApplicationToken) == 0 {
-		return buildValidationFailedError("ApplicationToken cannot be empty")
+	validation := validation{}
+
+	validation.required("ApplicationToken", request.ApplicationToken)
+
+	return validation.err()
+}
+
+// CheckAgainstStatus returns a validation error if this request can't be applied to an
+// application with the given status, for example because the order ID can only be changed
+// once the application is completed, or because the new amount isn't less than the current one.
+// Every problem found is listed in FieldErrors.
+func (request UpdateRequest) CheckAgainstStatus(status StatusResponse) *PASDKError {
+	validation := validation{}
+
+	if request.OrderID != nil && !status.Status.CanUpdateOrderID() {
+		validation.fail("OrderID", FieldErrorWrongStatus, "OrderID can only be changed when the application's status is "+
+			"\"completed\", but it is \""+string(status.Status)+"\"")
 	}
 
-	return nil
+	if request.Amount != nil && !status.Status.CanUpdateAmountOrExpiry() {
+		validation.fail("Amount", FieldErrorWrongStatus, "Amount can only be changed when the application's status is "+
+			"\"pending\", \"in_progress\" or \"pending_capture\", but it is \""+string(status.Status)+"\"")
+	}
+
+	if request.ExpiresIn != nil && !status.Status.CanUpdateAmountOrExpiry() {
+		validation.fail("ExpiresIn", FieldErrorWrongStatus, "ExpiresIn can only be changed when the application's status is "+
+			"\"pending\", \"in_progress\" or \"pending_capture\", but it is \""+string(status.Status)+"\"")
+	}
+
+	if request.Amount != nil && *request.Amount >= status.Amount {
+		validation.fail("Amount", FieldErrorNotLessThanCurrent, "field Amount must be less than the application's "+
+			"current amount of "+toString(status.Amount))
+	}
+
+	return validation.err()
 }
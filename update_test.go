@@ -9,7 +9,7 @@ func Test_Update(t *testing.T) {
 		return
 	}
 
-	amount := 100000
+	amount := Money(100000)
 	orderID := "neworderid"
 	expiresIn := 600
 
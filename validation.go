@@ -0,0 +1,87 @@
+package pasdk
+
+import "strings"
+
+// FieldErrorCode identifies the kind of problem a FieldError describes, so that it can be
+// handled without parsing its message.
+type FieldErrorCode string
+
+// The kinds of problem a request's fields can have.
+const (
+	FieldErrorRequired                FieldErrorCode = "required"                   // The field is empty but has to be set.
+	FieldErrorNotPositive             FieldErrorCode = "not_positive"               // The field has to be greater than 0.
+	FieldErrorInvalidPostcode         FieldErrorCode = "invalid_postcode"           // The field isn't a valid UK postcode.
+	FieldErrorInvalidTelephone        FieldErrorCode = "invalid_telephone"          // The field isn't a valid UK telephone number.
+	FieldErrorNotMobile               FieldErrorCode = "not_mobile"                 // The field is a valid telephone number, but not a mobile number.
+	FieldErrorUnsupportedFileType     FieldErrorCode = "unsupported_file_type"      // The field is a file type that can't be uploaded.
+	FieldErrorFileTypeMismatch        FieldErrorCode = "file_type_mismatch"         // The file's contents don't match its FileType.
+	FieldErrorFileTooLarge            FieldErrorCode = "file_too_large"             // The file is larger than the client's maximum invoice size.
+	FieldErrorUnknownPlan             FieldErrorCode = "unknown_plan"               // The plan ID isn't one of the account's plans.
+	FieldErrorAmountOutsidePlanLimits FieldErrorCode = "amount_outside_plan_limits" // The amount is less than the plan's minimum or more than its maximum.
+	FieldErrorWrongStatus             FieldErrorCode = "wrong_status"               // The field can't be used while the application has its current status.
+	FieldErrorNotLessThanCurrent      FieldErrorCode = "not_less_than_current"      // The amount isn't less than the application's current amount.
+)
+
+// FieldError describes a problem with a single field of a request, found before the
+// request was sent.
+type FieldError struct {
+	Field   string         // The name of the request field, such as "CustomerPostcode".
+	Code    FieldErrorCode // The kind of problem, such as FieldErrorRequired.
+	Message string         // A description of the problem, such as "CustomerPostcode cannot be empty".
+}
+
+// Error returns the message describing the problem.
+func (err FieldError) Error() string {
+	return err.Message
+}
+
+// Collects the problems found while validating a request, so that they can all be
+// reported at once.
+type validation struct {
+	fieldErrors []FieldError
+}
+
+// Records a problem with the given field.
+func (validation *validation) fail(field string, code FieldErrorCode, message string) {
+	validation.fieldErrors = append(validation.fieldErrors, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Records a problem found by another check, if there was one.
+func (validation *validation) check(problem *FieldError) {
+	if problem != nil {
+		validation.fieldErrors = append(validation.fieldErrors, *problem)
+	}
+}
+
+// Records a problem if value is empty.
+func (validation *validation) required(field string, value string) {
+	if len(value) == 0 {
+		validation.fail(field, FieldErrorRequired, field+" cannot be empty")
+	}
+}
+
+// Returns a validation error listing every problem found, or nil if there were none.
+func (validation *validation) err() *PASDKError {
+	if len(validation.fieldErrors) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(validation.fieldErrors))
+
+	for _, fieldError := range validation.fieldErrors {
+		messages = append(messages, fieldError.Message)
+	}
+
+	paErr := buildValidationFailedError(strings.Join(messages, "; "))
+	paErr.FieldErrors = validation.fieldErrors
+
+	return paErr
+}
+
+// Returns a validation error for a single problem.
+func buildFieldValidationError(problem FieldError) *PASDKError {
+	validation := validation{}
+	validation.check(&problem)
+
+	return validation.err()
+}
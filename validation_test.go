@@ -0,0 +1,109 @@
+package pasdk
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_BeginRequest_Validate_ReturnsEveryProblem(t *testing.T) {
+	telephone := "020 7946 0000"
+	sendSMS := true
+
+	err := BeginRequest{
+		CustomerFirstName: "Test",
+		CustomerAddress1:  "Test House",
+		CustomerPostcode:  "garbage",
+		CustomerTelephone: &telephone,
+		SendSMS:           &sendSMS,
+	}.Validate()
+
+	if err == nil || !err.IsValidationFailedError || !errors.Is(err, ErrValidationFailed) {
+		t.Fatal(err)
+	}
+
+	expected := []FieldError{
+		{"OrderID", FieldErrorRequired, "OrderID cannot be empty"},
+		{"Amount", FieldErrorNotPositive, "field Amount must be greater than 0"},
+		{"CustomerLastName", FieldErrorRequired, "CustomerLastName cannot be empty"},
+		{"CustomerPostcode", FieldErrorInvalidPostcode, "CustomerPostcode is not a valid UK postcode"},
+		{"CustomerTelephone", FieldErrorNotMobile, "CustomerTelephone must be a UK mobile number if SendSMS is true"},
+	}
+
+	checkFieldErrors(t, err, expected)
+
+	if err.Error() != "OrderID cannot be empty; field Amount must be greater than 0; CustomerLastName cannot be empty; "+
+		"CustomerPostcode is not a valid UK postcode; CustomerTelephone must be a UK mobile number if SendSMS is true" {
+		t.Error(err.Error())
+	}
+}
+
+func Test_Validate_ValidRequests(t *testing.T) {
+	requests := []interface{ Validate() *PASDKError }{
+		AccountRequest{},
+		BeginRequest{OrderID: "1", Amount: 100, CustomerFirstName: "Test", CustomerLastName: "Test",
+			CustomerAddress1: "Test House", CustomerPostcode: "sw1a1aa"},
+		CaptureRequest{ApplicationToken: "test"},
+		InvoiceRequest{ApplicationToken: "test", FileData: []byte("Invoice")},
+		InvoiceStreamRequest{ApplicationToken: "test", FilePath: "invoice.pdf"},
+		PlanRequest{Amount: 100},
+		PreapprovalRequest{CustomerFirstName: "Test", CustomerLastName: "Test", CustomerAddress1: "Test House",
+			CustomerPostcode: "SW1A 1AA"},
+		StatusRequest{ApplicationToken: "test"},
+		UpdateRequest{ApplicationToken: "test"},
+	}
+
+	for _, request := range requests {
+		if err := request.Validate(); err != nil {
+			t.Errorf("%T: %v", request, err)
+		}
+	}
+}
+
+func Test_InvoiceRequest_Validate_ChecksFileType(t *testing.T) {
+	err := InvoiceRequest{FileType: "png", FileData: []byte("Invoice")}.Validate()
+
+	if err == nil || len(err.FieldErrors) != 2 ||
+		err.FieldErrors[0].Code != FieldErrorRequired || err.FieldErrors[1].Code != FieldErrorUnsupportedFileType {
+		t.Error(err)
+	}
+
+	err = InvoiceStreamRequest{ApplicationToken: "test", FileType: "PNG"}.Validate()
+
+	if err == nil || len(err.FieldErrors) != 2 || err.FieldErrors[0].Field != "File" || err.FieldErrors[1].Field != "FileType" {
+		t.Error(err)
+	}
+}
+
+func Test_Fetch_ReturnsFieldErrors(t *testing.T) {
+	client := NewClient(PAAuth{APIKey: "key", APISecret: "secret"})
+
+	_, err := client.Preapproval(PreapprovalRequest{CustomerFirstName: "Test"})
+
+	if err == nil || len(err.FieldErrors) != 3 {
+		t.Fatal(err)
+	}
+
+	if err.Error() != "request is invalid: CustomerLastName cannot be empty; CustomerAddress1 cannot be empty; "+
+		"CustomerPostcode cannot be empty" {
+		t.Error(err.Error())
+	}
+}
+
+// Fails the test unless err has exactly the expected field errors, in order.
+func checkFieldErrors(t *testing.T, err *PASDKError, expected []FieldError) {
+	t.Helper()
+
+	if err == nil || !err.IsValidationFailedError {
+		t.Fatal(err)
+	}
+
+	if len(err.FieldErrors) != len(expected) {
+		t.Fatal(err.FieldErrors)
+	}
+
+	for i := range expected {
+		if err.FieldErrors[i] != expected[i] {
+			t.Error(err.FieldErrors[i])
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Package webhook receives the webhooks Payment Assist sends to the WebhookURL given
+// in a BeginRequest. It parses each webhook into a typed event, validates it and
+// passes it to the callbacks you register on a Handler.
+//
+// Webhooks aren't signed, so anyone who can reach your webhook URL can send one. Before
+// acting on a webhook, such as fulfilling an order when an application is completed,
+// make sure it is genuine: use Handler.RequireSecret to only accept webhooks sent to a
+// URL containing a secret, and Handler.VerifyWithAPI to check each application's status
+// with the API before your callbacks are called. If you use Parse directly, treat the
+// event as untrusted and check the application's status with Client.StatusContext or
+// StatusRequest.Fetch from the pasdk package.
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+)
+
+// The largest webhook body that will be read. Real webhooks are far smaller than this.
+const maxBodySize = 1 << 20
+
+// Event contains the data sent in a webhook. It is sent whenever an application's status changes.
+type Event struct {
+	ApplicationToken       string                  // The token representing the application.
+	Status                 pasdk.ApplicationStatus // The application's new status.
+	OrderID                string                  // The order ID given when the application was begun, if any.
+	PaymentAssistReference string                  // Payment Assist's reference for the application. This is empty until the application is completed.
+	Amount                 *pasdk.Money            // The amount of the application in pence, if it was sent.
+	ReceivedAt             time.Time               // The time the webhook was received.
+	Raw                    []byte                  // The unparsed body of the webhook.
+}
+
+// StatusChangedEvent is dispatched for every webhook, whatever the application's new status.
+type StatusChangedEvent struct {
+	Event
+}
+
+// CompletedEvent is dispatched when an application has been completed and the
+// finance facility or payment has been created.
+type CompletedEvent struct {
+	Event
+}
+
+// DeclinedEvent is dispatched when an application has been declined.
+type DeclinedEvent struct {
+	Event
+}
+
+// ExpiredEvent is dispatched when an application has expired before being completed.
+type ExpiredEvent struct {
+	Event
+}
+
+// PendingCaptureEvent is dispatched when an application is waiting to be captured, which
+// only happens when auto-capture is disabled.
+type PendingCaptureEvent struct {
+	Event
+}
+
+// The fields of a webhook payload, which may be sent as JSON or as a form.
+type payload struct {
+	Token   string          `json:"token"`
+	Status  string          `json:"status"`
+	OrderID string          `json:"order_id"`
+	PARef   string          `json:"pa_ref"`
+	Amount  json.RawMessage `json:"amount"`
+}
+
+// Parse reads a webhook from an incoming request and validates it. The body may be
+// JSON or a URL-encoded form. You only need this if you aren't using Handler.
+//
+// Parse only checks that the webhook is well formed, not that it was sent by Payment
+// Assist, so check the application's status with Client.StatusContext or StatusRequest.Fetch
+// from the pasdk package before acting on it.
+func Parse(request *http.Request) (*Event, error) {
+	if request.Method != http.MethodPost {
+		return nil, errors.New("webhooks must be sent using POST, not " + request.Method)
+	}
+
+	body, err := readBody(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+
+	var data payload
+
+	if mediaType == "application/x-www-form-urlencoded" {
+		data, err = parseForm(body)
+	} else {
+		err = json.Unmarshal(body, &data)
+	}
+
+	if err != nil {
+		return nil, errors.New("failed to parse webhook: " + err.Error())
+	}
+
+	event := Event{
+		ApplicationToken:       strings.TrimSpace(data.Token),
+		Status:                 pasdk.ApplicationStatus(strings.TrimSpace(data.Status)),
+		OrderID:                data.OrderID,
+		PaymentAssistReference: data.PARef,
+		ReceivedAt:             time.Now(),
+		Raw:                    body,
+	}
+
+	event.Amount, err = parseAmount(data.Amount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateEvent(event)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func readBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil {
+		return nil, errors.New("webhook body was empty")
+	}
+
+	body, err := io.ReadAll(io.LimitReader(request.Body, maxBodySize+1))
+
+	if err != nil {
+		return nil, errors.New("failed to read webhook body: " + err.Error())
+	}
+
+	if len(body) > maxBodySize {
+		return nil, errors.New("webhook body was too large")
+	}
+
+	if len(body) == 0 {
+		return nil, errors.New("webhook body was empty")
+	}
+
+	return body, nil
+}
+
+func parseForm(body []byte) (payload, error) {
+	values, err := url.ParseQuery(string(body))
+
+	if err != nil {
+		return payload{}, err
+	}
+
+	data := payload{
+		Token:   values.Get("token"),
+		Status:  values.Get("status"),
+		OrderID: values.Get("order_id"),
+		PARef:   values.Get("pa_ref"),
+	}
+
+	if values.Has("amount") {
+		data.Amount = json.RawMessage(strconv.Quote(values.Get("amount")))
+	}
+
+	return data, nil
+}
+
+// The amount may be sent as a number or as a string containing a number.
+func parseAmount(raw json.RawMessage) (*pasdk.Money, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	text := string(raw)
+
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+
+	amount, err := strconv.Atoi(text)
+
+	if err != nil {
+		return nil, errors.New("webhook amount was not a whole number of pence: " + string(raw))
+	}
+
+	money := pasdk.Money(amount)
+
+	return &money, nil
+}
+
+func validateEvent(event Event) error {
+	if len(event.ApplicationToken) == 0 {
+		return errors.New("webhook token cannot be empty")
+	}
+
+	if len(event.Status) == 0 {
+		return errors.New("webhook status cannot be empty")
+	}
+
+	if !event.Status.IsKnown() {
+		return errors.New("webhook status \"" + string(event.Status) + "\" is not recognised")
+	}
+
+	return nil
+}
@@ -0,0 +1,250 @@
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"sync"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+)
+
+// Handler is an http.Handler that receives webhooks from Payment Assist and passes
+// them to the callbacks registered on it. Register your callbacks before the handler
+// starts serving requests.
+//
+// If the webhook is invalid, the handler responds with 400 Bad Request. If any callback
+// returns an error, the handler responds with 500 Internal Server Error so that the
+// webhook can be delivered again; callbacks should therefore be safe to run more than
+// once for the same event. Otherwise the handler responds with 200 OK.
+//
+// By default the handler trusts every webhook it receives, but webhooks aren't signed,
+// so anyone who can reach the handler could send a forged one. Use RequireSecret and
+// VerifyWithAPI to make sure webhooks are genuine before your callbacks are called.
+type Handler struct {
+	mutex            sync.RWMutex
+	secret           string
+	verifyClient     *pasdk.Client
+	onStatusChanged  []func(context.Context, StatusChangedEvent) error
+	onCompleted      []func(context.Context, CompletedEvent) error
+	onDeclined       []func(context.Context, DeclinedEvent) error
+	onExpired        []func(context.Context, ExpiredEvent) error
+	onPendingCapture []func(context.Context, PendingCaptureEvent) error
+	onError          func(*http.Request, error)
+}
+
+// NewHandler creates a handler with no callbacks registered.
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// OnStatusChanged registers a callback that is called for every valid webhook.
+func (handler *Handler) OnStatusChanged(callback func(context.Context, StatusChangedEvent) error) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.onStatusChanged = append(handler.onStatusChanged, callback)
+}
+
+// OnCompleted registers a callback that is called when an application is completed.
+func (handler *Handler) OnCompleted(callback func(context.Context, CompletedEvent) error) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.onCompleted = append(handler.onCompleted, callback)
+}
+
+// OnDeclined registers a callback that is called when an application is declined.
+func (handler *Handler) OnDeclined(callback func(context.Context, DeclinedEvent) error) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.onDeclined = append(handler.onDeclined, callback)
+}
+
+// OnExpired registers a callback that is called when an application expires.
+func (handler *Handler) OnExpired(callback func(context.Context, ExpiredEvent) error) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.onExpired = append(handler.onExpired, callback)
+}
+
+// OnPendingCapture registers a callback that is called when an application is
+// waiting to be captured.
+func (handler *Handler) OnPendingCapture(callback func(context.Context, PendingCaptureEvent) error) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.onPendingCapture = append(handler.onPendingCapture, callback)
+}
+
+// OnError registers a callback that is called whenever a webhook is rejected or a
+// callback fails, which you may want to use for logging. Only one can be registered;
+// registering another replaces it.
+func (handler *Handler) OnError(callback func(*http.Request, error)) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.onError = callback
+}
+
+// The query parameter of the webhook URL that holds the secret checked by RequireSecret.
+const secretParameter = "secret"
+
+// RequireSecret makes the handler reject webhooks with 401 Unauthorized unless the URL
+// they were sent to has a "secret" query parameter equal to secret. Add the parameter
+// to the WebhookURL you pass in each BeginRequest, for example
+// "https://example.com/webhook?secret=...", and keep the secret private.
+func (handler *Handler) RequireSecret(secret string) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.secret = secret
+}
+
+// VerifyWithAPI makes the handler fetch each application's status using the given client
+// before dispatching a webhook, so that forged webhooks can't trigger your callbacks.
+// The event's Status, Amount and PaymentAssistReference are replaced with those returned
+// by the API. If the API refuses the request, for example because the token is unknown,
+// the webhook is rejected with 400 Bad Request. If the status can't be fetched for any
+// other reason, such as a network failure, the handler responds with 500 Internal Server
+// Error so that the webhook is delivered again.
+func (handler *Handler) VerifyWithAPI(client *pasdk.Client) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	handler.verifyClient = client
+}
+
+// ServeHTTP parses the webhook and dispatches it to the registered callbacks.
+func (handler *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	handler.mutex.RLock()
+	secret, verifyClient := handler.secret, handler.verifyClient
+	handler.mutex.RUnlock()
+
+	if len(secret) > 0 && subtle.ConstantTimeCompare([]byte(request.URL.Query().Get(secretParameter)), []byte(secret)) != 1 {
+		handler.reportError(request, errors.New("webhook was sent without the correct secret"))
+		http.Error(writer, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	event, err := Parse(request)
+
+	if err != nil {
+		handler.reportError(request, err)
+
+		if request.Method != http.MethodPost {
+			writer.Header().Set("Allow", http.MethodPost)
+			http.Error(writer, err.Error(), http.StatusMethodNotAllowed)
+			return
+		}
+
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if verifyClient != nil {
+		if verifyErr := verifyEvent(request.Context(), verifyClient, event); verifyErr != nil {
+			handler.reportError(request, verifyErr)
+
+			// Delivering the webhook again won't help if the API doesn't recognise the
+			// application, but it might if the API couldn't be reached or was throttling.
+			if verifyErr.IsRequestRefusedError && !verifyErr.IsThrottledError {
+				http.Error(writer, "webhook could not be verified", http.StatusBadRequest)
+			} else {
+				http.Error(writer, "failed to verify webhook", http.StatusInternalServerError)
+			}
+
+			return
+		}
+	}
+
+	err = handler.Dispatch(request.Context(), *event)
+
+	if err != nil {
+		handler.reportError(request, err)
+		http.Error(writer, "failed to process webhook", http.StatusInternalServerError)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Replaces the status and details of the event with those fetched from the API.
+func verifyEvent(ctx context.Context, client *pasdk.Client, event *Event) *pasdk.PASDKError {
+	status, err := client.StatusContext(ctx, pasdk.StatusRequest{ApplicationToken: event.ApplicationToken})
+
+	if err != nil {
+		return err.Wrap("failed to verify webhook: ")
+	}
+
+	amount := status.Amount
+
+	event.Status = status.Status
+	event.Amount = &amount
+	event.PaymentAssistReference = status.PaymentAssistReference
+
+	return nil
+}
+
+// Dispatch passes an event to the registered callbacks: first the OnStatusChanged callbacks,
+// then the callbacks for the event's status. It stops at the first callback that returns an error.
+// The event isn't verified, so only pass events you trust.
+func (handler *Handler) Dispatch(ctx context.Context, event Event) error {
+	// Copy the callbacks so the lock isn't held while they run, which would deadlock
+	// a callback that registers another.
+	handler.mutex.RLock()
+	onStatusChanged := append([]func(context.Context, StatusChangedEvent) error(nil), handler.onStatusChanged...)
+	onCompleted := append([]func(context.Context, CompletedEvent) error(nil), handler.onCompleted...)
+	onDeclined := append([]func(context.Context, DeclinedEvent) error(nil), handler.onDeclined...)
+	onExpired := append([]func(context.Context, ExpiredEvent) error(nil), handler.onExpired...)
+	onPendingCapture := append([]func(context.Context, PendingCaptureEvent) error(nil), handler.onPendingCapture...)
+	handler.mutex.RUnlock()
+
+	for _, callback := range onStatusChanged {
+		if err := callback(ctx, StatusChangedEvent{event}); err != nil {
+			return err
+		}
+	}
+
+	switch event.Status {
+	case pasdk.StatusCompleted:
+		for _, callback := range onCompleted {
+			if err := callback(ctx, CompletedEvent{event}); err != nil {
+				return err
+			}
+		}
+	case pasdk.StatusDeclined:
+		for _, callback := range onDeclined {
+			if err := callback(ctx, DeclinedEvent{event}); err != nil {
+				return err
+			}
+		}
+	case pasdk.StatusExpired:
+		for _, callback := range onExpired {
+			if err := callback(ctx, ExpiredEvent{event}); err != nil {
+				return err
+			}
+		}
+	case pasdk.StatusPendingCapture:
+		for _, callback := range onPendingCapture {
+			if err := callback(ctx, PendingCaptureEvent{event}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (handler *Handler) reportError(request *http.Request, err error) {
+	handler.mutex.RLock()
+	onError := handler.onError
+	handler.mutex.RUnlock()
+
+	if onError != nil {
+		onError(request, err)
+	}
+}
@@ -0,0 +1,334 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pasdk "github.com/paymentassist/paymentassist-go"
+)
+
+func Test_Handler_DispatchesTypedEvents(t *testing.T) {
+	handler := NewHandler()
+
+	var statusChanged []pasdk.ApplicationStatus
+	var completed CompletedEvent
+	declinedCalled := false
+
+	handler.OnStatusChanged(func(ctx context.Context, event StatusChangedEvent) error {
+		statusChanged = append(statusChanged, event.Status)
+		return nil
+	})
+	handler.OnCompleted(func(ctx context.Context, event CompletedEvent) error {
+		completed = event
+		return nil
+	})
+	handler.OnDeclined(func(ctx context.Context, event DeclinedEvent) error {
+		declinedCalled = true
+		return nil
+	})
+
+	request := httptest.NewRequest("POST", "/webhook", strings.NewReader(
+		`{"token":"aed3bd4e-c478-4d73-a6fa-3640a7155e4f","status":"completed","order_id":"123","pa_ref":"ref","amount":"50000"}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Error(recorder.Code, recorder.Body.String())
+	}
+	if len(statusChanged) != 1 || statusChanged[0] != pasdk.StatusCompleted {
+		t.Error(statusChanged)
+	}
+	if completed.ApplicationToken != "aed3bd4e-c478-4d73-a6fa-3640a7155e4f" {
+		t.Error()
+	}
+	if completed.OrderID != "123" || completed.PaymentAssistReference != "ref" {
+		t.Error()
+	}
+	if completed.Amount == nil || *completed.Amount != 50000 {
+		t.Error()
+	}
+	if declinedCalled {
+		t.Error()
+	}
+}
+
+func Test_Handler_AcceptsFormPayloads(t *testing.T) {
+	handler := NewHandler()
+
+	var declined DeclinedEvent
+
+	handler.OnDeclined(func(ctx context.Context, event DeclinedEvent) error {
+		declined = event
+		return nil
+	})
+
+	request := httptest.NewRequest("POST", "/webhook", strings.NewReader("token=abc&status=declined"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Error(recorder.Code)
+	}
+	if declined.ApplicationToken != "abc" {
+		t.Error()
+	}
+	if declined.Amount != nil {
+		t.Error()
+	}
+}
+
+func Test_Handler_RejectsInvalidWebhooks(t *testing.T) {
+	handler := NewHandler()
+
+	var reportedErrors []error
+
+	handler.OnError(func(request *http.Request, err error) {
+		reportedErrors = append(reportedErrors, err)
+	})
+
+	tests := []struct {
+		method       string
+		body         string
+		expectedCode int
+	}{
+		{"GET", "", http.StatusMethodNotAllowed},
+		{"POST", "", http.StatusBadRequest},
+		{"POST", "{not json", http.StatusBadRequest},
+		{"POST", `{"status":"completed"}`, http.StatusBadRequest},
+		{"POST", `{"token":"abc"}`, http.StatusBadRequest},
+		{"POST", `{"token":"abc","status":"unknown"}`, http.StatusBadRequest},
+		{"POST", `{"token":"abc","status":"completed","amount":"12.50"}`, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(test.method, "/webhook", strings.NewReader(test.body))
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expectedCode {
+			t.Error(test.body, recorder.Code)
+		}
+	}
+
+	if len(reportedErrors) != len(tests) {
+		t.Error(len(reportedErrors))
+	}
+}
+
+func Test_Handler_ReturnsServerError_WhenCallbackFails(t *testing.T) {
+	handler := NewHandler()
+
+	handler.OnExpired(func(ctx context.Context, event ExpiredEvent) error {
+		return errors.New("database unavailable")
+	})
+
+	request := httptest.NewRequest("POST", "/webhook", strings.NewReader(`{"token":"abc","status":"expired"}`))
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Error(recorder.Code)
+	}
+}
+
+func Test_Handler_RequireSecret_RejectsWrongSecret(t *testing.T) {
+	handler := NewHandler()
+	handler.RequireSecret("s3cret")
+
+	called := false
+
+	handler.OnCompleted(func(ctx context.Context, event CompletedEvent) error {
+		called = true
+		return nil
+	})
+
+	tests := []struct {
+		url          string
+		expectedCode int
+	}{
+		{"/webhook", http.StatusUnauthorized},
+		{"/webhook?secret=wrong", http.StatusUnauthorized},
+		{"/webhook?secret=s3cret", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest("POST", test.url, strings.NewReader(`{"token":"abc","status":"completed"}`))
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expectedCode {
+			t.Error(test.url, recorder.Code)
+		}
+	}
+
+	if !called {
+		t.Error()
+	}
+}
+
+func Test_Handler_VerifyWithAPI_UsesStatusFromAPI(t *testing.T) {
+	var requestedPaths []string
+
+	client := pasdk.NewClient(pasdk.PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+		pasdk.WithTransport(pasdk.RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			requestedPaths = append(requestedPaths, request.URL.Path)
+
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body: io.NopCloser(strings.NewReader(
+					`{"status":"ok","msg":null,"data":{"token":"abc","status":"declined","amount":10000,"pa_ref":null}}`)),
+			}, nil
+		})))
+
+	handler := NewHandler()
+	handler.VerifyWithAPI(client)
+
+	completedCalled := false
+	var declined DeclinedEvent
+
+	handler.OnCompleted(func(ctx context.Context, event CompletedEvent) error {
+		completedCalled = true
+		return nil
+	})
+	handler.OnDeclined(func(ctx context.Context, event DeclinedEvent) error {
+		declined = event
+		return nil
+	})
+
+	request := httptest.NewRequest("POST", "/webhook", strings.NewReader(`{"token":"abc","status":"completed","amount":"50000"}`))
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Error(recorder.Code)
+	}
+	if len(requestedPaths) != 1 || !strings.HasSuffix(requestedPaths[0], "/status") {
+		t.Error(requestedPaths)
+	}
+	if completedCalled {
+		t.Error()
+	}
+	if declined.ApplicationToken != "abc" || declined.Amount == nil || *declined.Amount != 10000 {
+		t.Error(declined)
+	}
+}
+
+func Test_Handler_VerifyWithAPI_RejectsWebhook_WhenStatusFails(t *testing.T) {
+	tests := []struct {
+		statusCode   int
+		body         string
+		transportErr error
+		expectedCode int
+	}{
+		{400, `{"status":"error","msg":"Invalid token","data":null}`, nil, http.StatusBadRequest},
+		{429, `{"status":"error","msg":"Too many requests","data":null}`, nil, http.StatusInternalServerError},
+		{500, `{"status":"error","msg":"Server error","data":null}`, nil, http.StatusInternalServerError},
+		{0, "", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		client := pasdk.NewClient(pasdk.PAAuth{APIKey: "key", APISecret: "secret", APIURL: "https://example.com"},
+			pasdk.WithTransport(pasdk.RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				if test.transportErr != nil {
+					return nil, test.transportErr
+				}
+
+				return &http.Response{
+					StatusCode: test.statusCode,
+					Header:     http.Header{},
+					Body:       io.NopCloser(strings.NewReader(test.body)),
+				}, nil
+			})))
+
+		handler := NewHandler()
+		handler.VerifyWithAPI(client)
+
+		called := false
+
+		handler.OnStatusChanged(func(ctx context.Context, event StatusChangedEvent) error {
+			called = true
+			return nil
+		})
+
+		request := httptest.NewRequest("POST", "/webhook", strings.NewReader(`{"token":"forged","status":"completed"}`))
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expectedCode {
+			t.Error(test.statusCode, test.transportErr, recorder.Code)
+		}
+		if called {
+			t.Error(test.statusCode)
+		}
+	}
+}
+
+func Test_Handler_Dispatch_AllowsCallbacksToRegisterCallbacks(t *testing.T) {
+	handler := NewHandler()
+
+	handler.OnStatusChanged(func(ctx context.Context, event StatusChangedEvent) error {
+		handler.OnCompleted(func(ctx context.Context, event CompletedEvent) error {
+			return nil
+		})
+		return nil
+	})
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- handler.Dispatch(context.Background(), Event{ApplicationToken: "abc", Status: pasdk.StatusCompleted})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Dispatch deadlocked")
+	}
+}
+
+func Test_parseAmount(t *testing.T) {
+	amount, err := parseAmount([]byte("12500"))
+
+	if err != nil || *amount != 12500 {
+		t.Error()
+	}
+
+	amount, err = parseAmount([]byte(`"12500"`))
+
+	if err != nil || *amount != 12500 {
+		t.Error()
+	}
+
+	amount, err = parseAmount([]byte("null"))
+
+	if err != nil || amount != nil {
+		t.Error()
+	}
+
+	_, err = parseAmount([]byte(`"£125"`))
+
+	if err == nil {
+		t.Error()
+	}
+}